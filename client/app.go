@@ -14,6 +14,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	registryv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -24,61 +25,194 @@ type App struct {
 	Image     string `json:"image"`
 	Framework string `json:"framework"`
 	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// +optional
+	Description string `json:"description"`
+	// +optional
+	AppVersion string `json:"app_version"`
+	// +optional
 	Cname []string `json:"cnames,omitempty"`
 	// +optional
+	IgnoreExternalCnames bool `json:"ignore_external_cnames"`
+	// +optional
+	GenerateDefaultCname bool `json:"generate_default_cname"`
+	// +readonly
+	DefaultCname string `json:"default_cname"`
+	// +readonly
+	URLs []string `json:"urls"`
+	// +readonly
+	CnameStatus []*CnameStatus `json:"cname_status"`
+	// +optional
 	Ports []int `json:"ports"`
 	// +optional
 	Units int64 `json:"units"`
 	// +optional
+	DeploymentsCount int64 `json:"deployments_count"`
+	// +readonly
+	TotalUnits int64 `json:"total_units"`
+	// +optional
+	State string `json:"state"`
+	// +readonly
+	Phase string `json:"phase"`
+	// +optional
 	Processes []*ProcessParameters `json:"processes,omitempty"`
 	// +optional
 	RoutingSettings *RoutingSettings `json:"routing_settings,omitempty"`
 	// +optional
 	Version int64 `json:"version"`
+	// +optional
+	DeploymentLabels map[string]string `json:"deployment_labels,omitempty"`
+	// +optional
+	Deployments []*Deployment `json:"deployment,omitempty"`
+	// +optional
+	DockerRegistry *DockerRegistry `json:"docker_registry,omitempty"`
+	// +optional
+	Builder string `json:"builder"`
+	// +optional
+	BuildPacks []string `json:"buildpacks,omitempty"`
+	// +optional
+	Canary *Canary `json:"canary,omitempty"`
+	// +readonly
+	CanaryActive bool `json:"canary_active"`
+	// +readonly
+	CanaryCurrentStep int64 `json:"canary_current_step"`
+	// +readonly
+	CanarySchedule []*CanaryStep `json:"canary_schedule"`
+
+	// RemovedLabels and RemovedAnnotations hold keys removed from the configuration since the last update,
+	// other keys present on the app are not managed by the provider and kept as is.
+	RemovedLabels      []string `json:"-"`
+	RemovedAnnotations []string `json:"-"`
+	// RemovedCnames holds cnames removed from the configuration since the last update,
+	// it is used when cnames added outside of the app are ignored.
+	RemovedCnames []string `json:"-"`
 }
 
 // ProcessParameters defines process parameters
 type ProcessParameters struct {
-	Cmd  []string `json:"cmd"`
-	Name string   `json:"name"`
+	Cmd   []string `json:"cmd"`
+	Name  string   `json:"name"`
+	Units int64    `json:"units"`
+	State string   `json:"state"`
 }
 
+const (
+	// AppStateRunning means that processes of an app have running units.
+	AppStateRunning = "running"
+	// AppStateStopped means that processes of an app are scaled down to zero units.
+	AppStateStopped = "stopped"
+)
+
 // RoutingSettings defines routing settings
 type RoutingSettings struct {
 	Weight int64 `json:"weight"`
 }
 
 func NewApp(input *v1beta1.App) *App {
+	// the schedule is informational, an app which canary can't be simulated has no schedule
+	schedule, _ := canarySchedule(input)
+
+	// top level attributes describe the latest deployment
 	var deployment v1beta1.AppDeploymentSpec
 	var ports []int
-	var processes []*ProcessParameters
+	var deployments []*Deployment
 	if len(input.Spec.Deployments) > 0 {
-		deployment = input.Spec.Deployments[0]
+		deployment = input.Spec.Deployments[len(input.Spec.Deployments)-1]
 		for _, port := range deployment.ExposedPorts {
 			ports = append(ports, port.Port)
 		}
 
-		for _, p := range deployment.Processes {
-			processes = append(processes, &ProcessParameters{
-				Name: p.Name,
-				Cmd:  p.Cmd,
-			})
+		for _, d := range input.Spec.Deployments {
+			deployments = append(deployments, newDeployment(d))
 		}
 	}
 
+	var dockerRegistry *DockerRegistry
+	if input.Spec.DockerRegistry.SecretName != "" {
+		dockerRegistry = &DockerRegistry{
+			SecretName: input.Spec.DockerRegistry.SecretName,
+		}
+	}
+
+	var appVersion string
+	if input.Spec.Version != nil {
+		appVersion = *input.Spec.Version
+	}
+
 	return &App{
-		Name:      input.ObjectMeta.Name,
-		Image:     deployment.Image,
-		Framework: input.Spec.Framework,
-		Cname:     input.Spec.Ingress.Cnames,
-		Ports:     ports,
-		Units:     int64(input.Spec.DeploymentsCount),
-		Processes: processes,
+		Name:                 input.ObjectMeta.Name,
+		Image:                deployment.Image,
+		Framework:            input.Spec.Framework,
+		Labels:               input.ObjectMeta.Labels,
+		Annotations:          input.ObjectMeta.Annotations,
+		Description:          input.Spec.Description,
+		AppVersion:           appVersion,
+		Cname:                input.Spec.Ingress.Cnames,
+		GenerateDefaultCname: input.Spec.Ingress.GenerateDefaultCname,
+		Ports:                ports,
+		Units:                int64(commonUnits(deployment.Processes)),
+		DeploymentsCount:     int64(input.Spec.DeploymentsCount),
+		TotalUnits:           int64(input.Units()),
+		State:                appState(input),
+		Phase:                string(input.Phase()),
+		Processes:            newProcesses(deployment.Processes),
 		RoutingSettings: &RoutingSettings{
 			int64(deployment.RoutingSettings.Weight),
 		},
-		Version: int64(deployment.Version),
+		Version:           int64(deployment.Version),
+		DeploymentLabels:  labelsMap(deployment.Labels),
+		Deployments:       deployments,
+		DockerRegistry:    dockerRegistry,
+		Builder:           input.Spec.Builder,
+		BuildPacks:        input.Spec.BuildPacks,
+		Canary:            newCanary(input.Spec.Canary),
+		CanaryActive:      input.Spec.Canary.Active,
+		CanaryCurrentStep: int64(input.Spec.Canary.CurrentStep),
+		CanarySchedule:    schedule,
+	}
+}
+
+// processUnits returns the number of units of the process, a process without units is run by ketch with a single unit.
+func processUnits(process v1beta1.ProcessSpec) int {
+	if process.Units == nil {
+		return v1beta1.DefaultNumberOfUnits
+	}
+	return *process.Units
+}
+
+func processState(process v1beta1.ProcessSpec) string {
+	if processUnits(process) == 0 {
+		return AppStateStopped
 	}
+	return AppStateRunning
+}
+
+// appState returns a state of the app based on its phase, a created app has no running units and is treated as stopped.
+func appState(app *v1beta1.App) string {
+	switch app.Phase() {
+	case v1beta1.AppCreated:
+		return AppStateStopped
+	case v1beta1.AppRunning:
+		return AppStateRunning
+	}
+	if app.Units() == 0 {
+		return AppStateStopped
+	}
+	return AppStateRunning
+}
+
+// commonUnits returns the number of units shared by all processes or 0 if processes have different units.
+func commonUnits(processes []v1beta1.ProcessSpec) int {
+	units := 0
+	for i, p := range processes {
+		if i > 0 && processUnits(p) != units {
+			return 0
+		}
+		units = processUnits(p)
+	}
+	return units
 }
 
 func newExposedPort(port string) (*v1beta1.ExposedPort, error) {
@@ -96,97 +230,131 @@ func newExposedPort(port string) (*v1beta1.ExposedPort, error) {
 	}, nil
 }
 
-//nolint:gocyclo
-func (a *App) convertToKetchApp() *v1beta1.App {
-	cfg, err := getImageConfig(a.Image)
-	if err != nil {
-		log.Println("#### GetImageConfig:ERR ", err)
+// latestDeployment returns a deployment defined by the top level attributes of the app.
+func (a *App) latestDeployment() *Deployment {
+	var weight int64 = 100
+	if a.RoutingSettings != nil && a.RoutingSettings.Weight > 0 {
+		weight = a.RoutingSettings.Weight
 	}
 
-	var cmd []string
-	if cfg != nil {
-		cmd = make([]string, 0, len(cfg.Config.Entrypoint))
-		cmd = append(cmd, cfg.Config.Entrypoint...)
-		cmd = append(cmd, cfg.Config.Cmd...)
+	return &Deployment{
+		Version:   a.Version,
+		Image:     a.Image,
+		Processes: a.Processes,
+		Weight:    weight,
+		Ports:     a.Ports,
+	}
+}
+
+// deployments returns deployments of the app, either listed explicitly or the latest one.
+func (a *App) deployments() []*Deployment {
+	if len(a.Deployments) > 0 {
+		return a.Deployments
+	}
+	return []*Deployment{a.latestDeployment()}
+}
+
+func (a *App) convertToKetchApp() (*v1beta1.App, error) {
+	if err := ValidateDeployments(a.Deployments); err != nil {
+		return nil, err
 	}
 
 	app := &v1beta1.App{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: a.Name,
+			Name:        a.Name,
+			Labels:      a.Labels,
+			Annotations: a.Annotations,
 		},
 		Spec: v1beta1.AppSpec{
-			Framework: a.Framework,
-			Deployments: []v1beta1.AppDeploymentSpec{
-				{
-					Image: a.Image,
-				},
-			},
+			Framework:   a.Framework,
+			Description: a.Description,
 		},
 	}
 
-	if len(a.Ports) > 0 {
-		for _, port := range a.Ports {
-			app.Spec.Deployments[0].ExposedPorts = append(app.Spec.Deployments[0].ExposedPorts, v1beta1.ExposedPort{
-				Port:     port,
-				Protocol: "TCP",
-			})
-		}
-	} else if cfg != nil {
-		var exposedPorts []v1beta1.ExposedPort
-		for port := range cfg.Config.ExposedPorts {
-			exposedPort, err := newExposedPort(port)
-			if err == nil {
-				exposedPorts = append(exposedPorts, *exposedPort)
-			}
-		}
+	if a.AppVersion != "" {
+		app.Spec.Version = &a.AppVersion
+	}
+
+	// images built by a builder are not inspected, their processes and ports are defined by the build
+	inspectImage := a.Builder == ""
+	deployments := a.deployments()
+	for _, deployment := range deployments {
+		d := *deployment
+		d.Labels = withLabels(a.DeploymentLabels, deployment.Labels)
+		app.Spec.Deployments = append(app.Spec.Deployments, d.convertToKetchDeployment(inspectImage))
+	}
+
+	app.Spec.Builder = a.Builder
+	app.Spec.BuildPacks = a.BuildPacks
 
-		app.Spec.Deployments[0].ExposedPorts = exposedPorts
+	if a.DockerRegistry != nil {
+		app.Spec.DockerRegistry.SecretName = a.DockerRegistry.SecretName
 	}
 
-	if len(app.Spec.Deployments[0].ExposedPorts) == 0 {
-		app.Spec.Deployments[0].ExposedPorts = append(app.Spec.Deployments[0].ExposedPorts, v1beta1.ExposedPort{
-			Port:     8000,
-			Protocol: "TCP",
-		})
+	if a.Canary != nil {
+		canary, err := a.Canary.convertToKetchCanary()
+		if err != nil {
+			return nil, err
+		}
+		app.Spec.Canary = canary
 	}
 
-	app.Spec.Ingress.GenerateDefaultCname = true
+	app.Spec.Ingress.GenerateDefaultCname = a.GenerateDefaultCname
 	if len(a.Cname) > 0 {
 		app.Spec.Ingress.Cnames = append(app.Spec.Ingress.Cnames, a.Cname...)
 	}
 
 	// default 1
-	if a.Units == 0 {
+	if a.DeploymentsCount == 0 {
 		app.Spec.DeploymentsCount = 1
 	} else {
-		app.Spec.DeploymentsCount = int(a.Units)
+		app.Spec.DeploymentsCount = int(a.DeploymentsCount)
 	}
 
-	if len(a.Processes) > 0 {
-		for _, p := range a.Processes {
-			app.Spec.Deployments[0].Processes = append(app.Spec.Deployments[0].Processes, v1beta1.ProcessSpec{
-				Name: p.Name,
-				Cmd:  p.Cmd,
-			})
+	if a.Units > 0 {
+		if err := app.SetUnits(v1beta1.NewSelector(0, ""), int(a.Units)); err != nil {
+			return nil, err
 		}
-	} else if len(cmd) > 0 {
-		app.Spec.Deployments[0].Processes = append(app.Spec.Deployments[0].Processes, v1beta1.ProcessSpec{
-			Name: "web",
-			Cmd:  cmd,
-		})
 	}
-
-	if a.RoutingSettings != nil && a.RoutingSettings.Weight > 0 {
-		app.Spec.Deployments[0].RoutingSettings.Weight = uint8(a.RoutingSettings.Weight)
-	} else {
-		app.Spec.Deployments[0].RoutingSettings.Weight = 100
+	for _, deployment := range deployments {
+		for _, p := range deployment.Processes {
+			if p.Units > 0 && p.Name != "" {
+				if err := app.SetUnits(v1beta1.NewSelector(int(deployment.Version), p.Name), int(p.Units)); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 
-	if a.Version > 0 {
-		app.Spec.Deployments[0].Version = v1beta1.DeploymentVersion(a.Version)
+	return app, nil
+}
+
+// applyState starts or stops processes of the app according to the state of the app and its processes.
+func (a *App) applyState(app *v1beta1.App) error {
+	if err := setState(app, v1beta1.NewSelector(0, ""), a.State); err != nil {
+		return err
 	}
+	for _, deployment := range a.deployments() {
+		for _, p := range deployment.Processes {
+			if p.Name == "" {
+				continue
+			}
+			if err := setState(app, v1beta1.NewSelector(int(deployment.Version), p.Name), p.State); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-	return app
+func setState(app *v1beta1.App, selector v1beta1.Selector, state string) error {
+	switch state {
+	case AppStateRunning:
+		return app.Start(selector)
+	case AppStateStopped:
+		return app.Stop(selector)
+	}
+	return nil
 }
 
 // Wrapf wraps error and supplies the line and the file where the error occurred.
@@ -215,7 +383,28 @@ func (c *Client) GetApp(ctx context.Context, name string) (*App, error) {
 		return nil, err
 	}
 
-	return NewApp(app), nil
+	result := NewApp(app)
+	// urls of the app depend on the ingress controller of its framework
+	framework, err := c.getFramework(ctx, app.Spec.Framework)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+	if framework != nil {
+		result.setURLs(app, framework)
+		result.CnameStatus, err = c.cnameStatus(ctx, app, framework)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// setURLs sets the default cname and urls the app is accessible by.
+func (a *App) setURLs(app *v1beta1.App, framework *v1beta1.Framework) {
+	if defaultCname := app.DefaultCname(framework); defaultCname != nil {
+		a.DefaultCname = *defaultCname
+	}
+	a.URLs = app.CNames(framework)
 }
 
 func (c *Client) DeleteApp(ctx context.Context, name string) error {
@@ -243,8 +432,34 @@ func (c *Client) getApp(ctx context.Context, name string) (*v1beta1.App, error)
 	return app, nil
 }
 
+// applyAppRegistrySecret creates an image pull secret of the app if the app asks for it.
+func (c *Client) applyAppRegistrySecret(ctx context.Context, input *App) error {
+	if input.DockerRegistry == nil || !input.DockerRegistry.CreateSecret {
+		return nil
+	}
+	return c.ApplyRegistrySecret(ctx, input.Framework, input.DockerRegistry.SecretName)
+}
+
+// NextDeploymentVersion returns a version for a new deployment of the app.
+func (c *Client) NextDeploymentVersion(ctx context.Context, name string) (int64, error) {
+	app, err := c.getApp(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return nextDeploymentVersion(app), nil
+}
+
 func (c *Client) CreateApp(ctx context.Context, input *App) error {
-	app := input.convertToKetchApp()
+	app, err := input.convertToKetchApp()
+	if err != nil {
+		return err
+	}
+	if err := input.applyState(app); err != nil {
+		return err
+	}
+	if err := c.applyAppRegistrySecret(ctx, input); err != nil {
+		return err
+	}
 	return c.kube.Create(ctx, app)
 }
 
@@ -254,7 +469,55 @@ func (c *Client) UpdateApp(ctx context.Context, input *App) error {
 		return err
 	}
 
-	updates := input.convertToKetchApp()
+	updates, err := input.convertToKetchApp()
+	if err != nil {
+		return err
+	}
+	if err := c.applyAppRegistrySecret(ctx, input); err != nil {
+		return err
+	}
+	if len(input.Deployments) == 0 {
+		updates.Spec.Deployments, err = mergeLatestDeployment(updates.Spec.Deployments[0], app.Spec.Deployments, app.Spec.Canary.Active)
+		if err != nil {
+			return err
+		}
+		for i := range updates.Spec.Deployments {
+			setDeploymentLabels(&updates.Spec.Deployments[i], input.DeploymentLabels)
+		}
+	}
+	preserveUnits(updates.Spec.Deployments, app.Spec.Deployments)
+	if err := input.applyState(updates); err != nil {
+		return err
+	}
+	// an active canary and the deployments counter are driven by ketch controller
+	if app.Spec.Canary.Active {
+		updates.Spec.Canary = app.Spec.Canary
+	}
+	if app.Spec.DeploymentsCount > updates.Spec.DeploymentsCount {
+		updates.Spec.DeploymentsCount = app.Spec.DeploymentsCount
+	}
+	input.keepExternalCnames(updates, app)
+	app.Labels = mergeMetadata(app.Labels, input.Labels, input.RemovedLabels)
+	app.Annotations = mergeMetadata(app.Annotations, input.Annotations, input.RemovedAnnotations)
 	app.Spec = updates.Spec
 	return c.kube.Update(ctx, app)
 }
+
+// keepExternalCnames keeps cnames of the current app which are not declared by the input if the input ignores them.
+func (a *App) keepExternalCnames(updates *v1beta1.App, current *v1beta1.App) {
+	if a.IgnoreExternalCnames {
+		updates.Spec.Ingress.Cnames = mergeCnames(current.Spec.Ingress.Cnames, a.Cname, a.RemovedCnames)
+	}
+}
+
+// preserveUnits copies units of processes from the current deployments to processes without units,
+// so units managed outside of the app (e.g. by ketch_app_scale) are not reset by an update.
+func preserveUnits(deployments []v1beta1.AppDeploymentSpec, current []v1beta1.AppDeploymentSpec) {
+	for _, deployment := range deployments {
+		for _, currentDeployment := range current {
+			if currentDeployment.Version == deployment.Version {
+				copyUnits(deployment.Processes, currentDeployment.Processes)
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// maxCanarySteps is the maximum number of steps of a canary allowed by the App CRD.
+const maxCanarySteps = 100
+
+// Canary defines how traffic is shifted to a new deployment of an app.
+type Canary struct {
+	Steps        int64  `json:"steps"`
+	StepWeight   int64  `json:"step_weight"`
+	StepInterval string `json:"step_interval"`
+}
+
+func newCanary(input v1beta1.CanarySpec) *Canary {
+	if input.Steps == 0 {
+		return nil
+	}
+
+	return &Canary{
+		Steps:        int64(input.Steps),
+		StepWeight:   int64(input.StepWeight),
+		StepInterval: input.StepTimeInteval.String(),
+	}
+}
+
+func (c *Canary) convertToKetchCanary() (v1beta1.CanarySpec, error) {
+	interval, err := time.ParseDuration(c.StepInterval)
+	if err != nil {
+		return v1beta1.CanarySpec{}, err
+	}
+
+	return v1beta1.CanarySpec{
+		Steps:           int(c.Steps),
+		StepWeight:      uint8(c.StepWeight),
+		StepTimeInteval: interval,
+	}, nil
+}
+
+// activate returns an active canary starting at the given time.
+func (c *Canary) activate(now time.Time) (v1beta1.CanarySpec, error) {
+	canary, err := c.convertToKetchCanary()
+	if err != nil {
+		return v1beta1.CanarySpec{}, err
+	}
+
+	started := metav1.NewTime(now)
+	next := metav1.NewTime(now.Add(canary.StepTimeInteval))
+	canary.NextScheduledTime = &next
+	canary.Started = &started
+	canary.Active = true
+	return canary, nil
+}
+
+// StartCanary adds a new deployment of the app with zero weight and activates a canary,
+// ketch controller then shifts traffic to the new deployment step by step.
+func (c *Client) StartCanary(ctx context.Context, input *App) error {
+	if input.Canary == nil {
+		return errors.New("canary is not configured")
+	}
+
+	app, err := c.getApp(ctx, input.Name)
+	if err != nil {
+		return err
+	}
+	if app.Spec.Canary.Active {
+		return errors.New("canary is already active")
+	}
+	if len(app.Spec.Deployments) != 1 {
+		return errors.New("canary requires exactly one running deployment")
+	}
+
+	updates, err := input.convertToKetchApp()
+	if err != nil {
+		return err
+	}
+	if err := c.applyAppRegistrySecret(ctx, input); err != nil {
+		return err
+	}
+
+	current := app.Spec.Deployments[0]
+	current.RoutingSettings.Weight = 100
+	setDeploymentLabels(&current, input.DeploymentLabels)
+
+	target := updates.Spec.Deployments[0]
+	version := nextDeploymentVersion(app)
+	target.Version = v1beta1.DeploymentVersion(version)
+	target.RoutingSettings.Weight = 0
+	copyUnits(target.Processes, current.Processes)
+
+	updates.Spec.Canary, err = input.Canary.activate(time.Now())
+	if err != nil {
+		return err
+	}
+	updates.Spec.Deployments = []v1beta1.AppDeploymentSpec{current, target}
+	updates.Spec.DeploymentsCount = int(version)
+	if err := input.applyState(updates); err != nil {
+		return err
+	}
+
+	input.keepExternalCnames(updates, app)
+	app.Labels = mergeMetadata(app.Labels, input.Labels, input.RemovedLabels)
+	app.Annotations = mergeMetadata(app.Annotations, input.Annotations, input.RemovedAnnotations)
+	app.Spec = updates.Spec
+	return c.kube.Update(ctx, app)
+}
+
+// WaitForCanary waits until ketch controller completes an active canary of the app.
+// If failureThreshold is set and the canary deployment stays unhealthy longer than the threshold,
+// the canary is rolled back and an error with the found problems is returned.
+func (c *Client) WaitForCanary(ctx context.Context, name string, timeout, failureThreshold time.Duration) error {
+	var step int
+	var unhealthySince time.Time
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		app, err := c.getApp(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		step = app.Spec.Canary.CurrentStep
+		if !app.Spec.Canary.Active {
+			return true, nil
+		}
+		if failureThreshold == 0 {
+			return false, nil
+		}
+
+		latest := app.Spec.Deployments[len(app.Spec.Deployments)-1]
+		problems, err := c.deploymentProblems(ctx, app, latest.Version)
+		if err != nil {
+			return false, err
+		}
+		if len(problems) == 0 {
+			unhealthySince = time.Time{}
+			return false, nil
+		}
+		if unhealthySince.IsZero() {
+			unhealthySince = time.Now()
+		}
+		if time.Since(unhealthySince) < failureThreshold {
+			return false, nil
+		}
+
+		reason := strings.Join(problems, "; ")
+		err = c.RollbackApp(ctx, &AppRollback{App: name, Reason: reason})
+		if err != nil {
+			return false, err
+		}
+		return false, fmt.Errorf("canary of app %q was rolled back after being unhealthy for %s: %s", name, failureThreshold, reason)
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timeout waiting for canary of app %q to complete, current step is %d", name, step)
+	}
+	return err
+}
+
+// CanaryStep describes traffic weights of deployments after a step of a canary.
+type CanaryStep struct {
+	Step          int64  `json:"step"`
+	Time          string `json:"time"`
+	PrimaryWeight int64  `json:"primary_weight"`
+	CanaryWeight  int64  `json:"canary_weight"`
+	// Promoted is true when the canary deployment becomes the only deployment of the app.
+	Promoted bool `json:"promoted"`
+}
+
+// Schedule simulates a canary of an app running a single deployment started at the given time.
+func (c *Canary) Schedule(start time.Time) ([]*CanaryStep, error) {
+	canary, err := c.activate(start)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &v1beta1.App{
+		Spec: v1beta1.AppSpec{
+			Canary: canary,
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 100}},
+				{Version: 2, RoutingSettings: v1beta1.RoutingSettings{Weight: 0}},
+			},
+		},
+	}
+	return canarySchedule(app)
+}
+
+// canarySchedule simulates the remaining steps of an active canary of the app using App.DoCanary.
+func canarySchedule(input *v1beta1.App) ([]*CanaryStep, error) {
+	app := input.DeepCopy()
+
+	var steps []*CanaryStep
+	for app.Spec.Canary.Active {
+		if len(steps) >= maxCanarySteps {
+			return nil, fmt.Errorf("canary does not complete in %d steps", maxCanarySteps)
+		}
+		if app.Spec.Canary.NextScheduledTime == nil {
+			return nil, errors.New("canary is active but the next step is not scheduled")
+		}
+
+		now := *app.Spec.Canary.NextScheduledTime
+		if err := app.DoCanary(now); err != nil {
+			return nil, err
+		}
+
+		step := &CanaryStep{
+			Step: int64(app.Spec.Canary.CurrentStep),
+			Time: now.UTC().Format(time.RFC3339),
+		}
+		if len(app.Spec.Deployments) == 1 {
+			step.CanaryWeight = int64(app.Spec.Deployments[0].RoutingSettings.Weight)
+			step.Promoted = true
+		} else {
+			step.PrimaryWeight = int64(app.Spec.Deployments[0].RoutingSettings.Weight)
+			step.CanaryWeight = int64(app.Spec.Deployments[1].RoutingSettings.Weight)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
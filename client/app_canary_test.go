@@ -0,0 +1,56 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanarySchedule(t *testing.T) {
+	canary := &Canary{
+		Steps:        3,
+		StepWeight:   33,
+		StepInterval: "10m",
+	}
+	start := time.Date(2021, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	schedule, err := canary.Schedule(start)
+	require.NoError(t, err)
+	require.Equal(t, []*CanaryStep{
+		{Step: 1, Time: "2021-08-01T10:10:00Z", PrimaryWeight: 67, CanaryWeight: 33},
+		{Step: 2, Time: "2021-08-01T10:20:00Z", PrimaryWeight: 34, CanaryWeight: 66},
+		{Step: 3, Time: "2021-08-01T10:30:00Z", CanaryWeight: 100, Promoted: true},
+	}, schedule)
+}
+
+func TestCanaryScheduleInvalidInterval(t *testing.T) {
+	canary := &Canary{
+		Steps:        2,
+		StepWeight:   50,
+		StepInterval: "ten minutes",
+	}
+	_, err := canary.Schedule(time.Now())
+	require.Error(t, err)
+}
+
+func TestCanaryScheduleMaxSteps(t *testing.T) {
+	canary := &Canary{
+		Steps:        maxCanarySteps,
+		StepWeight:   1,
+		StepInterval: "1m",
+	}
+	schedule, err := canary.Schedule(time.Now())
+	require.NoError(t, err)
+	require.Len(t, schedule, maxCanarySteps)
+	require.True(t, schedule[maxCanarySteps-1].Promoted)
+
+	// a canary which doesn't shift traffic completes after its steps only
+	canary = &Canary{
+		Steps:        maxCanarySteps + 1,
+		StepWeight:   0,
+		StepInterval: "1m",
+	}
+	_, err = canary.Schedule(time.Now())
+	require.EqualError(t, err, "canary does not complete in 100 steps")
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+// certificateListKind is a kind of cert-manager certificates, cert-manager types are not vendored,
+// so certificates are read as unstructured objects.
+var certificateListKind = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "CertificateList",
+}
+
+// CnameStatus describes how a cname of an app is served.
+type CnameStatus struct {
+	Cname  string `json:"cname"`
+	Scheme string `json:"scheme"`
+	// CertificateExists shows if there is a cert-manager certificate for the cname.
+	CertificateExists bool `json:"certificate_exists"`
+	// CertificateReady shows if the certificate is issued.
+	CertificateReady bool `json:"certificate_ready"`
+	// CertificateUnknown shows that certificates can't be read, because cert-manager is not installed
+	// or certificates can't be listed, so CertificateExists and CertificateReady are not known.
+	CertificateUnknown bool `json:"certificate_unknown"`
+}
+
+// cnameStatus returns statuses of cnames of the app, cnames are served over https if the framework has a cluster issuer.
+func (c *Client) cnameStatus(ctx context.Context, app *v1beta1.App, framework *v1beta1.Framework) ([]*CnameStatus, error) {
+	if len(app.Spec.Ingress.Cnames) == 0 {
+		return nil, nil
+	}
+
+	// certificates are issued only for cnames served over https
+	scheme := "http"
+	var certificates []unstructured.Unstructured
+	known := true
+	if len(framework.Spec.IngressController.ClusterIssuer) > 0 {
+		scheme = "https"
+		var err error
+		certificates, known, err = c.certificates(ctx, framework.Spec.NamespaceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []*CnameStatus
+	for _, cname := range app.Spec.Ingress.Cnames {
+		status := &CnameStatus{
+			Cname:              cname,
+			Scheme:             scheme,
+			CertificateUnknown: !known,
+		}
+		for _, certificate := range certificates {
+			if !certificateHasDNSName(certificate, cname) {
+				continue
+			}
+			status.CertificateExists = true
+			status.CertificateReady = status.CertificateReady || certificateReady(certificate)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// certificates returns cert-manager certificates of the namespace.
+// Certificates are unknown without cert-manager or without permissions to list certificates.
+func (c *Client) certificates(ctx context.Context, namespace string) ([]unstructured.Unstructured, bool, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(certificateListKind)
+	err := c.kube.List(ctx, list, client.InNamespace(namespace))
+	if meta.IsNoMatchError(err) || k8serrors.IsForbidden(err) {
+		log.Printf("certificates of namespace %q are unknown: %v\n", namespace, err)
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return list.Items, true, nil
+}
+
+func certificateHasDNSName(certificate unstructured.Unstructured, name string) bool {
+	names, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func certificateReady(certificate unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Ready" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForCertificates waits until certificates of all cnames of the app served over https are issued.
+func (c *Client) WaitForCertificates(ctx context.Context, name string, timeout time.Duration) error {
+	var pending []string
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		app, err := c.GetApp(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		pending = nil
+		for _, status := range app.CnameStatus {
+			if status.Scheme != "https" {
+				continue
+			}
+			if status.CertificateUnknown {
+				return false, fmt.Errorf("certificates of app %q can't be read, cert-manager is not installed or certificates can't be listed", name)
+			}
+			if !status.CertificateReady {
+				pending = append(pending, status.Cname)
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timeout waiting for certificates of app %q, pending cnames: %s", name, strings.Join(pending, ", "))
+	}
+	return err
+}
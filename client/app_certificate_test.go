@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func newCertificate(name string, dnsName string, ready bool) *unstructured.Unstructured {
+	status := "False"
+	if ready {
+		status = "True"
+	}
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"dnsNames": []interface{}{dnsName},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": status},
+			},
+		},
+	}}
+	certificate.SetAPIVersion("cert-manager.io/v1")
+	certificate.SetKind("Certificate")
+	certificate.SetNamespace("ketch-testfw")
+	certificate.SetName(name)
+	return certificate
+}
+
+func TestCnameStatus(t *testing.T) {
+	framework := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec: v1beta1.FrameworkSpec{
+			Name:          "testfw",
+			NamespaceName: "ketch-testfw",
+			IngressController: v1beta1.IngressControllerSpec{
+				ClusterIssuer: "letsencrypt",
+			},
+		},
+	}
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Framework: "testfw",
+			Ingress: v1beta1.IngressSpec{
+				Cnames: v1beta1.CnameList{"ready.example.com", "pending.example.com", "missing.example.com"},
+			},
+		},
+	}
+	c := newFakeClient(t, framework, app,
+		newCertificate("testapp-ready", "ready.example.com", true),
+		newCertificate("testapp-pending", "pending.example.com", false),
+	)
+
+	result, err := c.GetApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, []*CnameStatus{
+		{Cname: "ready.example.com", Scheme: "https", CertificateExists: true, CertificateReady: true},
+		{Cname: "pending.example.com", Scheme: "https", CertificateExists: true},
+		{Cname: "missing.example.com", Scheme: "https"},
+	}, result.CnameStatus)
+
+	err = c.WaitForCertificates(context.Background(), "testapp", time.Millisecond)
+	require.EqualError(t, err, `timeout waiting for certificates of app "testapp", pending cnames: pending.example.com, missing.example.com`)
+}
+
+// forbiddenCertificates denies listing of cert-manager certificates like a cluster without RBAC rules for them.
+type forbiddenCertificates struct {
+	client.Client
+}
+
+func (c forbiddenCertificates) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*unstructured.UnstructuredList); ok {
+		return k8serrors.NewForbidden(schema.GroupResource{Group: "cert-manager.io", Resource: "certificates"}, "", errors.New("forbidden"))
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestCnameStatusForbidden(t *testing.T) {
+	framework := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec: v1beta1.FrameworkSpec{
+			Name:          "testfw",
+			NamespaceName: "ketch-testfw",
+			IngressController: v1beta1.IngressControllerSpec{
+				ClusterIssuer: "letsencrypt",
+			},
+		},
+	}
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Framework: "testfw",
+			Ingress:   v1beta1.IngressSpec{Cnames: v1beta1.CnameList{"testapp.example.com"}},
+		},
+	}
+	c := newFakeClient(t, framework, app)
+	c.kube = forbiddenCertificates{Client: c.kube}
+
+	// statuses of certificates are unknown without permissions to list them
+	result, err := c.GetApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, []*CnameStatus{
+		{Cname: "testapp.example.com", Scheme: "https", CertificateUnknown: true},
+	}, result.CnameStatus)
+
+	// unknown certificates are not waited for
+	err = c.WaitForCertificates(context.Background(), "testapp", time.Minute)
+	require.EqualError(t, err, `certificates of app "testapp" can't be read, cert-manager is not installed or certificates can't be listed`)
+}
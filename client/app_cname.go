@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+// ErrCnameNotFound is returned when an app doesn't have the requested cname.
+var ErrCnameNotFound = errors.New("cname not found")
+
+// AppCname defines a single cname of an app, e.g. a hostname provisioned apart from the app.
+type AppCname struct {
+	App   string `json:"app"`
+	Cname string `json:"cname"`
+}
+
+func (c *Client) GetAppCname(ctx context.Context, input *AppCname) (*AppCname, error) {
+	app, err := c.getApp(ctx, input.App)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasCname(app.Spec.Ingress.Cnames, input.Cname) {
+		return nil, ErrCnameNotFound
+	}
+	return &AppCname{
+		App:   input.App,
+		Cname: input.Cname,
+	}, nil
+}
+
+// AddAppCname adds the cname to the app.
+// A cname the app already has is managed elsewhere, e.g. by ketch_app, so it's not adopted.
+func (c *Client) AddAppCname(ctx context.Context, input *AppCname) error {
+	app, err := c.getApp(ctx, input.App)
+	if err != nil {
+		return err
+	}
+
+	if hasCname(app.Spec.Ingress.Cnames, input.Cname) {
+		return appCnameExists(input)
+	}
+	app.Spec.Ingress.Cnames = append(app.Spec.Ingress.Cnames, input.Cname)
+	return c.kube.Update(ctx, app)
+}
+
+// RemoveAppCname removes the cname from the app, a removed app is ignored.
+func (c *Client) RemoveAppCname(ctx context.Context, input *AppCname) error {
+	app, err := c.getApp(ctx, input.App)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !hasCname(app.Spec.Ingress.Cnames, input.Cname) {
+		return nil
+	}
+	var cnames v1beta1.CnameList
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if cname != input.Cname {
+			cnames = append(cnames, cname)
+		}
+	}
+	app.Spec.Ingress.Cnames = cnames
+	return c.kube.Update(ctx, app)
+}
+
+// CheckAppCname checks that the cname can be added to the app,
+// the cname must not be used by other apps and the app must not have the cname yet.
+func (c *Client) CheckAppCname(ctx context.Context, input *AppCname) error {
+	if err := c.CheckCnames(ctx, input.App, []string{input.Cname}); err != nil {
+		return err
+	}
+	app, err := c.getApp(ctx, input.App)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if hasCname(app.Spec.Ingress.Cnames, input.Cname) {
+		return appCnameExists(input)
+	}
+	return nil
+}
+
+func appCnameExists(input *AppCname) error {
+	return fmt.Errorf("app %q already has cname %q, import it with id %q or remove it from the app", input.App, input.Cname, input.App+":"+input.Cname)
+}
+
+// CheckCnames checks that cnames are not repeated and are not used by other apps than the named one.
+func (c *Client) CheckCnames(ctx context.Context, name string, cnames []string) error {
+	for i, cname := range cnames {
+		if hasCname(cnames[:i], cname) {
+			return fmt.Errorf("cname %q is repeated", cname)
+		}
+	}
+	if len(cnames) == 0 {
+		return nil
+	}
+
+	apps := &v1beta1.AppList{}
+	if err := c.kube.List(ctx, apps); err != nil {
+		return err
+	}
+	for _, app := range apps.Items {
+		if app.Name == name {
+			continue
+		}
+		for _, cname := range cnames {
+			if hasCname(app.Spec.Ingress.Cnames, cname) {
+				return fmt.Errorf("cname %q is already used by app %q", cname, app.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func hasCname(cnames []string, cname string) bool {
+	for _, c := range cnames {
+		if c == cname {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCnames sets declared cnames keeping cnames added outside of the app, e.g. by ketch_app_cname,
+// cnames removed from the declared ones since the last update are dropped.
+func mergeCnames(current []string, declared []string, removed []string) v1beta1.CnameList {
+	var cnames v1beta1.CnameList
+	cnames = append(cnames, declared...)
+	for _, cname := range current {
+		if hasCname(declared, cname) || hasCname(removed, cname) {
+			continue
+		}
+		cnames = append(cnames, cname)
+	}
+	return cnames
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestAppCname(t *testing.T) {
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Ingress: v1beta1.IngressSpec{Cnames: v1beta1.CnameList{"testapp.example.com"}},
+		},
+	}
+	c := newFakeClient(t, app)
+	cname := &AppCname{App: "testapp", Cname: "vanity.example.com"}
+
+	_, err := c.GetAppCname(context.Background(), cname)
+	require.True(t, errors.Is(err, ErrCnameNotFound))
+
+	require.NoError(t, c.CheckAppCname(context.Background(), cname))
+	require.NoError(t, c.AddAppCname(context.Background(), cname))
+	// a cname the app already has is not adopted
+	require.EqualError(t, c.AddAppCname(context.Background(), cname), `app "testapp" already has cname "vanity.example.com", import it with id "testapp:vanity.example.com" or remove it from the app`)
+	require.Error(t, c.CheckAppCname(context.Background(), &AppCname{App: "testapp", Cname: "testapp.example.com"}))
+	require.NoError(t, c.CheckAppCname(context.Background(), &AppCname{App: "newapp", Cname: "newapp.example.com"}))
+	result, err := c.GetAppCname(context.Background(), cname)
+	require.NoError(t, err)
+	require.Equal(t, cname, result)
+
+	updated, err := c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, v1beta1.CnameList{"testapp.example.com", "vanity.example.com"}, updated.Spec.Ingress.Cnames)
+
+	require.NoError(t, c.RemoveAppCname(context.Background(), cname))
+	updated, err = c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, v1beta1.CnameList{"testapp.example.com"}, updated.Spec.Ingress.Cnames)
+
+	// a removed app has no cnames to remove
+	require.NoError(t, c.RemoveAppCname(context.Background(), &AppCname{App: "removed", Cname: "vanity.example.com"}))
+}
+
+func TestMergeCnames(t *testing.T) {
+	cnames := mergeCnames(
+		[]string{"old.example.com", "vanity.example.com", "testapp.example.com"},
+		[]string{"testapp.example.com", "new.example.com"},
+		[]string{"old.example.com"},
+	)
+	require.Equal(t, v1beta1.CnameList{"testapp.example.com", "new.example.com", "vanity.example.com"}, cnames)
+}
+
+func TestUpdateAppExternalCnames(t *testing.T) {
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Framework: "testfw",
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{Version: 1, Image: "gcr.io/test:1", RoutingSettings: v1beta1.RoutingSettings{Weight: 100}},
+			},
+			Ingress: v1beta1.IngressSpec{Cnames: v1beta1.CnameList{"testapp.example.com", "vanity.example.com"}},
+		},
+	}
+	c := newFakeClient(t, app)
+
+	input := &App{
+		Name:                 "testapp",
+		Image:                "gcr.io/test:2",
+		Framework:            "testfw",
+		Builder:              "paketobuildpacks/builder:full",
+		Cname:                []string{"new.example.com"},
+		IgnoreExternalCnames: true,
+		RemovedCnames:        []string{"testapp.example.com"},
+	}
+	require.NoError(t, c.UpdateApp(context.Background(), input))
+	updated, err := c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, v1beta1.CnameList{"new.example.com", "vanity.example.com"}, updated.Spec.Ingress.Cnames)
+
+	// cnames are replaced when external cnames are not ignored
+	input.IgnoreExternalCnames = false
+	require.NoError(t, c.UpdateApp(context.Background(), input))
+	updated, err = c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, v1beta1.CnameList{"new.example.com"}, updated.Spec.Ingress.Cnames)
+}
+
+func TestCheckCnames(t *testing.T) {
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Ingress: v1beta1.IngressSpec{Cnames: v1beta1.CnameList{"testapp.example.com"}},
+		},
+	}
+	c := newFakeClient(t, app)
+
+	require.NoError(t, c.CheckCnames(context.Background(), "testapp", []string{"testapp.example.com"}))
+	require.NoError(t, c.CheckCnames(context.Background(), "otherapp", []string{"otherapp.example.com"}))
+
+	err := c.CheckCnames(context.Background(), "otherapp", []string{"otherapp.example.com", "testapp.example.com"})
+	require.EqualError(t, err, `cname "testapp.example.com" is already used by app "testapp"`)
+
+	err = c.CheckCnames(context.Background(), "otherapp", []string{"otherapp.example.com", "otherapp.example.com"})
+	require.EqualError(t, err, `cname "otherapp.example.com" is repeated`)
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+	registryv1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Deployment defines a deployment of an app, an app may run several deployments to split traffic between them.
+type Deployment struct {
+	Version int64  `json:"version"`
+	Image   string `json:"image"`
+	// +optional
+	Processes []*ProcessParameters `json:"processes,omitempty"`
+	// +optional
+	Weight int64 `json:"weight"`
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// +optional
+	Ports []int `json:"ports"`
+}
+
+func newDeployment(input v1beta1.AppDeploymentSpec) *Deployment {
+	var ports []int
+	for _, port := range input.ExposedPorts {
+		ports = append(ports, port.Port)
+	}
+
+	return &Deployment{
+		Version:   int64(input.Version),
+		Image:     input.Image,
+		Processes: newProcesses(input.Processes),
+		Weight:    int64(input.RoutingSettings.Weight),
+		Labels:    labelsMap(input.Labels),
+		Ports:     ports,
+	}
+}
+
+func newProcesses(input []v1beta1.ProcessSpec) []*ProcessParameters {
+	var processes []*ProcessParameters
+	for _, p := range input {
+		processes = append(processes, &ProcessParameters{
+			Name:  p.Name,
+			Cmd:   p.Cmd,
+			Units: int64(processUnits(p)),
+			State: processState(p),
+		})
+	}
+	return processes
+}
+
+//nolint:gocyclo
+func (d *Deployment) convertToKetchDeployment(inspectImage bool) v1beta1.AppDeploymentSpec {
+	var cfg *registryv1.ConfigFile
+	if inspectImage {
+		var err error
+		cfg, err = getImageConfig(d.Image)
+		if err != nil {
+			log.Println("#### GetImageConfig:ERR ", err)
+		}
+	}
+
+	var cmd []string
+	if cfg != nil {
+		cmd = make([]string, 0, len(cfg.Config.Entrypoint))
+		cmd = append(cmd, cfg.Config.Entrypoint...)
+		cmd = append(cmd, cfg.Config.Cmd...)
+	}
+
+	deployment := v1beta1.AppDeploymentSpec{
+		Image:   d.Image,
+		Version: v1beta1.DeploymentVersion(d.Version),
+		RoutingSettings: v1beta1.RoutingSettings{
+			Weight: uint8(d.Weight),
+		},
+	}
+
+	if len(d.Ports) > 0 {
+		for _, port := range d.Ports {
+			deployment.ExposedPorts = append(deployment.ExposedPorts, v1beta1.ExposedPort{
+				Port:     port,
+				Protocol: "TCP",
+			})
+		}
+	} else if cfg != nil {
+		var exposedPorts []v1beta1.ExposedPort
+		for port := range cfg.Config.ExposedPorts {
+			exposedPort, err := newExposedPort(port)
+			if err == nil {
+				exposedPorts = append(exposedPorts, *exposedPort)
+			}
+		}
+
+		deployment.ExposedPorts = exposedPorts
+	}
+
+	if len(deployment.ExposedPorts) == 0 {
+		deployment.ExposedPorts = append(deployment.ExposedPorts, v1beta1.ExposedPort{
+			Port:     8000,
+			Protocol: "TCP",
+		})
+	}
+
+	if len(d.Processes) > 0 {
+		for _, p := range d.Processes {
+			deployment.Processes = append(deployment.Processes, v1beta1.ProcessSpec{
+				Name: p.Name,
+				Cmd:  p.Cmd,
+			})
+		}
+	} else if len(cmd) > 0 {
+		deployment.Processes = append(deployment.Processes, v1beta1.ProcessSpec{
+			Name: "web",
+			Cmd:  cmd,
+		})
+	}
+
+	deployment.Labels = ketchLabels(d.Labels)
+
+	return deployment
+}
+
+// nextDeploymentVersion returns a version for a new deployment of the app.
+func nextDeploymentVersion(app *v1beta1.App) int64 {
+	version := int64(app.Spec.DeploymentsCount)
+	for _, deployment := range app.Spec.Deployments {
+		if int64(deployment.Version) > version {
+			version = int64(deployment.Version)
+		}
+	}
+	return version + 1
+}
+
+// ValidateDeployments checks that deployments have unique positive versions and their weights total 100.
+func ValidateDeployments(deployments []*Deployment) error {
+	if len(deployments) == 0 {
+		return nil
+	}
+
+	versions := make(map[int64]struct{}, len(deployments))
+	var weight int64
+	for _, d := range deployments {
+		if d.Version <= 0 {
+			return fmt.Errorf("deployment version must be greater than 0, got %d", d.Version)
+		}
+		if _, ok := versions[d.Version]; ok {
+			return fmt.Errorf("deployment version %d is used by several deployments", d.Version)
+		}
+		versions[d.Version] = struct{}{}
+		weight += d.Weight
+	}
+	if weight != 100 {
+		return fmt.Errorf("weights of deployments must total 100, got %d", weight)
+	}
+	return nil
+}
+
+// mergeLatestDeployment replaces the latest deployment keeping previous deployments, e.g. during a canary,
+// the version and the weight of the current latest deployment are kept when there are other deployments.
+// The version of the latest deployment can't be changed during an active canary,
+// otherwise deployments of the canary would be collapsed into one deployment.
+func mergeLatestDeployment(latest v1beta1.AppDeploymentSpec, current []v1beta1.AppDeploymentSpec, canaryActive bool) ([]v1beta1.AppDeploymentSpec, error) {
+	if len(current) == 0 {
+		return []v1beta1.AppDeploymentSpec{latest}, nil
+	}
+
+	last := current[len(current)-1]
+	copyUnits(latest.Processes, last.Processes)
+	if len(current) == 1 {
+		return []v1beta1.AppDeploymentSpec{latest}, nil
+	}
+
+	if latest.Version == 0 {
+		latest.Version = last.Version
+	}
+	if canaryActive && latest.Version != last.Version {
+		return nil, fmt.Errorf("canary is active, version %d of the latest deployment can't be replaced by version %d", last.Version, latest.Version)
+	}
+	latest.RoutingSettings = last.RoutingSettings
+
+	var deployments []v1beta1.AppDeploymentSpec
+	for _, deployment := range current[:len(current)-1] {
+		if deployment.Version == latest.Version {
+			continue
+		}
+		deployments = append(deployments, deployment)
+	}
+	return append(deployments, latest), nil
+}
+
+// copyUnits copies units of processes from the current processes to processes without units.
+func copyUnits(processes []v1beta1.ProcessSpec, current []v1beta1.ProcessSpec) {
+	for i, process := range processes {
+		if process.Units != nil {
+			continue
+		}
+		for _, currentProcess := range current {
+			if currentProcess.Name == process.Name && currentProcess.Units != nil {
+				units := *currentProcess.Units
+				processes[i].Units = &units
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestValidateDeployments(t *testing.T) {
+	tests := []struct {
+		name        string
+		deployments []*Deployment
+		wantErr     bool
+	}{
+		{
+			name: "no deployments",
+		},
+		{
+			name: "weights total 100",
+			deployments: []*Deployment{
+				{Version: 1, Weight: 70},
+				{Version: 2, Weight: 30},
+			},
+		},
+		{
+			name: "weights do not total 100",
+			deployments: []*Deployment{
+				{Version: 1, Weight: 70},
+				{Version: 2, Weight: 20},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicated version",
+			deployments: []*Deployment{
+				{Version: 1, Weight: 50},
+				{Version: 1, Weight: 50},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeployments(tt.deployments)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMergeLatestDeployment(t *testing.T) {
+	latest := v1beta1.AppDeploymentSpec{
+		Image:           "gcr.io/test:3",
+		RoutingSettings: v1beta1.RoutingSettings{Weight: 100},
+	}
+	current := []v1beta1.AppDeploymentSpec{
+		{Image: "gcr.io/test:1", Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 70}},
+		{Image: "gcr.io/test:2", Version: 2, RoutingSettings: v1beta1.RoutingSettings{Weight: 30}},
+	}
+
+	deployments, err := mergeLatestDeployment(latest, current, true)
+	require.NoError(t, err)
+	require.Equal(t, []v1beta1.AppDeploymentSpec{
+		{Image: "gcr.io/test:1", Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 70}},
+		{Image: "gcr.io/test:3", Version: 2, RoutingSettings: v1beta1.RoutingSettings{Weight: 30}},
+	}, deployments)
+
+	deployments, err = mergeLatestDeployment(latest, current[:1], false)
+	require.NoError(t, err)
+	require.Equal(t, []v1beta1.AppDeploymentSpec{latest}, deployments)
+
+	// deployments of an active canary are not collapsed
+	latest.Version = 1
+	_, err = mergeLatestDeployment(latest, current, true)
+	require.EqualError(t, err, "canary is active, version 2 of the latest deployment can't be replaced by version 1")
+}
+
+func TestMergeLatestDeploymentKeepsUnits(t *testing.T) {
+	units := 3
+	latest := v1beta1.AppDeploymentSpec{
+		Image:     "gcr.io/test:2",
+		Version:   2,
+		Processes: []v1beta1.ProcessSpec{{Name: "web"}},
+	}
+	current := []v1beta1.AppDeploymentSpec{
+		{Image: "gcr.io/test:1", Version: 1, Processes: []v1beta1.ProcessSpec{{Name: "web", Units: &units}}},
+	}
+
+	deployments, err := mergeLatestDeployment(latest, current, false)
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	require.Equal(t, v1beta1.DeploymentVersion(2), deployments[0].Version)
+	require.Equal(t, &units, deployments[0].Processes[0].Units)
+}
+
+func TestNextDeploymentVersion(t *testing.T) {
+	app := &v1beta1.App{
+		Spec: v1beta1.AppSpec{
+			DeploymentsCount: 2,
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{Version: 4},
+			},
+		},
+	}
+	require.Equal(t, int64(5), nextDeploymentVersion(app))
+
+	app.Spec.Deployments = nil
+	require.Equal(t, int64(3), nextDeploymentVersion(app))
+}
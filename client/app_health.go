@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// appNameLabel and deploymentVersionLabel are set by ketch controller on pods of an app deployment.
+	appNameLabel           = "theketch.io/app-name"
+	deploymentVersionLabel = "theketch.io/app-deployment-version"
+)
+
+// podState returns a simplified state of the pod and a message explaining it.
+func podState(pod corev1.Pod) (v1beta1.PodState, string) {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return v1beta1.PodSucceeded, ""
+	case corev1.PodFailed:
+		return v1beta1.PodError, pod.Status.Message
+	}
+
+	ready := pod.Status.Phase == corev1.PodRunning
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ContainerCreating", "PodInitializing":
+			default:
+				return v1beta1.PodError, fmt.Sprintf("%s: %s", waiting.Reason, waiting.Message)
+			}
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return v1beta1.PodError, fmt.Sprintf("%s: %s", terminated.Reason, terminated.Message)
+		}
+		ready = ready && status.Ready
+	}
+	if ready {
+		return v1beta1.PodRunning, ""
+	}
+	return v1beta1.PodDeploying, "pod is not ready"
+}
+
+// deploymentProblems returns messages describing why the deployment of the app is not healthy,
+// it checks conditions of the app and states of pods of the deployment.
+func (c *Client) deploymentProblems(ctx context.Context, app *v1beta1.App, version v1beta1.DeploymentVersion) ([]string, error) {
+	var problems []string
+	for _, condition := range app.Status.Conditions {
+		if condition.Status == corev1.ConditionFalse {
+			problems = append(problems, fmt.Sprintf("condition %s: %s", condition.Type, condition.Message))
+		}
+	}
+
+	framework, err := c.getFramework(ctx, app.Spec.Framework)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := corev1.PodList{}
+	err = c.kube.List(ctx, &pods, client.InNamespace(framework.Spec.NamespaceName), client.MatchingLabels{
+		appNameLabel:           app.Name,
+		deploymentVersionLabel: version.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		state, message := podState(pod)
+		if state == v1beta1.PodError || state == v1beta1.PodDeploying {
+			problems = append(problems, fmt.Sprintf("pod %s is in %s state: %s", pod.Name, state, message))
+		}
+	}
+	return problems, nil
+}
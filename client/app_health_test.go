@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestPodState(t *testing.T) {
+	tests := []struct {
+		name   string
+		status corev1.PodStatus
+		want   v1beta1.PodState
+	}{
+		{
+			name: "running",
+			status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+			},
+			want: v1beta1.PodRunning,
+		},
+		{
+			name: "creating",
+			status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+				}},
+			},
+			want: v1beta1.PodDeploying,
+		},
+		{
+			name: "crash loop",
+			status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				}},
+			},
+			want: v1beta1.PodError,
+		},
+		{
+			name:   "succeeded",
+			status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+			want:   v1beta1.PodSucceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, _ := podState(corev1.Pod{Status: tt.status})
+			require.Equal(t, tt.want, state)
+		})
+	}
+}
+
+func TestDeploymentProblems(t *testing.T) {
+	framework := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec:       v1beta1.FrameworkSpec{Name: "testfw", NamespaceName: "ketch-testfw"},
+	}
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec:       v1beta1.AppSpec{Framework: "testfw"},
+		Status: v1beta1.AppStatus{
+			Conditions: []v1beta1.Condition{
+				{Type: v1beta1.Scheduled, Status: corev1.ConditionFalse, Message: "failed to render chart"},
+			},
+		},
+	}
+	pod := func(name, version string, status corev1.PodStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ketch-testfw",
+				Labels: map[string]string{
+					appNameLabel:           "testapp",
+					deploymentVersionLabel: version,
+				},
+			},
+			Status: status,
+		}
+	}
+	crashing := corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off"}},
+		}},
+	}
+	c := newFakeClient(t, framework, app,
+		pod("testapp-web-1", "1", crashing),
+		pod("testapp-web-2", "2", crashing),
+	)
+
+	problems, err := c.deploymentProblems(context.Background(), app, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"condition Scheduled: failed to render chart",
+		"pod testapp-web-2 is in error state: CrashLoopBackOff: back-off",
+	}, problems)
+}
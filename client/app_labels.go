@@ -0,0 +1,79 @@
+package client
+
+import (
+	"sort"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+// labelsMap converts labels of a deployment to a map, nil is returned for a deployment without labels.
+func labelsMap(labels []v1beta1.Label) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(labels))
+	for _, label := range labels {
+		values[label.Name] = label.Value
+	}
+	return values
+}
+
+// ketchLabels converts a map to labels of a deployment sorted by name, so the order is stable between updates.
+func ketchLabels(values map[string]string) []v1beta1.Label {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var labels []v1beta1.Label
+	for _, name := range names {
+		labels = append(labels, v1beta1.Label{
+			Name:  name,
+			Value: values[name],
+		})
+	}
+	return labels
+}
+
+// withLabels returns labels of a deployment extended by common labels, labels of the deployment take precedence.
+func withLabels(common map[string]string, labels map[string]string) map[string]string {
+	if len(common) == 0 {
+		return labels
+	}
+	values := make(map[string]string, len(common)+len(labels))
+	for name, value := range common {
+		values[name] = value
+	}
+	for name, value := range labels {
+		values[name] = value
+	}
+	return values
+}
+
+// setDeploymentLabels sets common labels on a deployment kept from the cluster, e.g. a primary deployment of a canary.
+func setDeploymentLabels(deployment *v1beta1.AppDeploymentSpec, common map[string]string) {
+	if len(common) == 0 {
+		return
+	}
+	deployment.Labels = ketchLabels(withLabels(labelsMap(deployment.Labels), common))
+}
+
+// mergeMetadata sets desired values on top of the current ones and drops removed keys,
+// so labels and annotations added by ketch controller or other tools are kept.
+func mergeMetadata(current map[string]string, desired map[string]string, removed []string) map[string]string {
+	values := make(map[string]string, len(current)+len(desired))
+	for key, value := range current {
+		values[key] = value
+	}
+	for _, key := range removed {
+		delete(values, key)
+	}
+	for key, value := range desired {
+		values[key] = value
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
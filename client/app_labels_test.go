@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestMergeMetadata(t *testing.T) {
+	current := map[string]string{
+		"team":                  "payments",
+		"tier":                  "backend",
+		"theketch.io/framework": "testfw",
+	}
+
+	merged := mergeMetadata(current, map[string]string{"team": "checkout"}, []string{"tier"})
+	require.Equal(t, map[string]string{
+		"team":                  "checkout",
+		"theketch.io/framework": "testfw",
+	}, merged)
+
+	require.Nil(t, mergeMetadata(nil, map[string]string{}, []string{"tier"}))
+}
+
+func TestConvertToKetchAppDeploymentLabels(t *testing.T) {
+	app := &App{
+		Name:             "testapp",
+		Framework:        "testfw",
+		Builder:          "paketobuildpacks/builder:full",
+		Labels:           map[string]string{"team": "payments"},
+		DeploymentLabels: map[string]string{"mesh": "enabled", "tier": "backend"},
+		Deployments: []*Deployment{
+			{Version: 1, Image: "gcr.io/test:1", Weight: 80},
+			{Version: 2, Image: "gcr.io/test:2", Weight: 20, Labels: map[string]string{"tier": "canary"}},
+		},
+	}
+
+	ketchApp, err := app.convertToKetchApp()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"team": "payments"}, ketchApp.Labels)
+	require.Equal(t, []v1beta1.Label{
+		{Name: "mesh", Value: "enabled"},
+		{Name: "tier", Value: "backend"},
+	}, ketchApp.Spec.Deployments[0].Labels)
+	require.Equal(t, []v1beta1.Label{
+		{Name: "mesh", Value: "enabled"},
+		{Name: "tier", Value: "canary"},
+	}, ketchApp.Spec.Deployments[1].Labels)
+
+	// labels of deployments are not changed by the conversion
+	require.Nil(t, app.Deployments[0].Labels)
+	require.Equal(t, map[string]string{"tier": "canary"}, app.Deployments[1].Labels)
+}
+
+func TestUpdateAppLabels(t *testing.T) {
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testapp",
+			Labels: map[string]string{
+				"team":                  "payments",
+				"theketch.io/framework": "testfw",
+			},
+			Annotations: map[string]string{"owner": "alice"},
+		},
+		Spec: v1beta1.AppSpec{
+			Framework: "testfw",
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{Version: 1, Image: "gcr.io/test:1", RoutingSettings: v1beta1.RoutingSettings{Weight: 50}},
+				{Version: 2, Image: "gcr.io/test:2", RoutingSettings: v1beta1.RoutingSettings{Weight: 50}},
+			},
+		},
+	}
+	c := newFakeClient(t, app)
+
+	err := c.UpdateApp(context.Background(), &App{
+		Name:               "testapp",
+		Image:              "gcr.io/test:3",
+		Framework:          "testfw",
+		Builder:            "paketobuildpacks/builder:full",
+		Labels:             map[string]string{"team": "checkout"},
+		DeploymentLabels:   map[string]string{"mesh": "enabled"},
+		RemovedAnnotations: []string{"owner"},
+	})
+	require.NoError(t, err)
+
+	updated, err := c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"team":                  "checkout",
+		"theketch.io/framework": "testfw",
+	}, updated.Labels)
+	require.Empty(t, updated.Annotations)
+	require.Len(t, updated.Spec.Deployments, 2)
+	for _, deployment := range updated.Spec.Deployments {
+		require.Equal(t, []v1beta1.Label{{Name: "mesh", Value: "enabled"}}, deployment.Labels)
+	}
+
+	converted := NewApp(updated)
+	require.Equal(t, map[string]string{"mesh": "enabled"}, converted.DeploymentLabels)
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AppRollback describes a rollback of a canary of an app.
+type AppRollback struct {
+	App     string `json:"app"`
+	Trigger string `json:"trigger"`
+	// +optional
+	Reason string `json:"reason"`
+	// +readonly
+	RolledBackAt string `json:"rolled_back_at"`
+	// +readonly
+	RemovedVersion int64 `json:"removed_version"`
+}
+
+// RollbackApp moves all traffic back to the primary deployment of the app, deactivates its canary
+// and removes the canary deployment. Deployments of an app without an active canary, e.g. split by ketch_app_traffic,
+// are not rolled back.
+func (c *Client) RollbackApp(ctx context.Context, input *AppRollback) error {
+	app, err := c.getApp(ctx, input.App)
+	if err != nil {
+		return err
+	}
+	if !app.Spec.Canary.Active {
+		return errors.New("app has no active canary to roll back")
+	}
+	if len(app.Spec.Deployments) < 2 {
+		return errors.New("app has no canary deployment to roll back")
+	}
+
+	app.DoRollback()
+	removed := app.Spec.Deployments[1]
+	app.Spec.Deployments = append(app.Spec.Deployments[:1], app.Spec.Deployments[2:]...)
+	app.Spec.Canary.NextScheduledTime = nil
+	app.Spec.Canary.CurrentStep = 0
+
+	err = c.kube.Update(ctx, app)
+	if err != nil {
+		return err
+	}
+
+	input.RemovedVersion = int64(removed.Version)
+	input.RolledBackAt = time.Now().UTC().Format(time.RFC3339)
+	return nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestRollbackApp(t *testing.T) {
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Canary: v1beta1.CanarySpec{Steps: 4, StepWeight: 25, CurrentStep: 2, Active: true},
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{Image: "gcr.io/test:1", Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 50}},
+				{Image: "gcr.io/test:2", Version: 2, RoutingSettings: v1beta1.RoutingSettings{Weight: 50}},
+			},
+		},
+	}
+	c := newFakeClient(t, app)
+
+	rollback := &AppRollback{App: "testapp", Trigger: "1"}
+	err := c.RollbackApp(context.Background(), rollback)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rollback.RemovedVersion)
+	require.NotEmpty(t, rollback.RolledBackAt)
+
+	updated, err := c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.False(t, updated.Spec.Canary.Active)
+	require.Equal(t, 0, updated.Spec.Canary.CurrentStep)
+	require.Equal(t, []v1beta1.AppDeploymentSpec{
+		{Image: "gcr.io/test:1", Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 100}},
+	}, updated.Spec.Deployments)
+
+	err = c.RollbackApp(context.Background(), rollback)
+	require.Error(t, err)
+}
+
+func TestRollbackAppWithoutCanary(t *testing.T) {
+	deployments := []v1beta1.AppDeploymentSpec{
+		{Image: "gcr.io/test:1", Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 80}},
+		{Image: "gcr.io/test:2", Version: 2, RoutingSettings: v1beta1.RoutingSettings{Weight: 20}},
+	}
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec:       v1beta1.AppSpec{Deployments: deployments},
+	}
+	c := newFakeClient(t, app)
+
+	// traffic split without a canary is kept
+	err := c.RollbackApp(context.Background(), &AppRollback{App: "testapp", Trigger: "1"})
+	require.EqualError(t, err, "app has no active canary to roll back")
+
+	updated, err := c.getApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, deployments, updated.Spec.Deployments)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+var (
+	// ErrDeploymentNotFound and ErrProcessNotFound are returned when an app has no selected deployment or process.
+	ErrDeploymentNotFound = v1beta1.ErrDeploymentNotFound
+	ErrProcessNotFound    = v1beta1.ErrProcessNotFound
+)
+
+// AppScale defines units of app processes selected by a process name and a deployment version.
+type AppScale struct {
+	App string `json:"app"`
+	// +optional
+	Process string `json:"process"`
+	// +optional
+	DeploymentVersion int64 `json:"deployment_version"`
+	Units             int64 `json:"units"`
+}
+
+func (s *AppScale) selector() v1beta1.Selector {
+	return v1beta1.NewSelector(int(s.DeploymentVersion), s.Process)
+}
+
+// GetAppScale returns units of the selected processes.
+// Units are -1 if the selected processes run different units.
+func (c *Client) GetAppScale(ctx context.Context, input *AppScale) (*AppScale, error) {
+	app, err := c.getApp(ctx, input.App)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := input.selector()
+	deploymentFound := false
+	var units []int
+	for _, deployment := range app.Spec.Deployments {
+		if selector.DeploymentVersion != nil && *selector.DeploymentVersion != deployment.Version {
+			continue
+		}
+		deploymentFound = true
+		for _, process := range deployment.Processes {
+			if selector.Process != nil && *selector.Process != process.Name {
+				continue
+			}
+			units = append(units, processUnits(process))
+		}
+	}
+	if selector.DeploymentVersion != nil && !deploymentFound {
+		return nil, ErrDeploymentNotFound
+	}
+	if len(units) == 0 {
+		return nil, ErrProcessNotFound
+	}
+
+	scale := &AppScale{
+		App:               input.App,
+		Process:           input.Process,
+		DeploymentVersion: input.DeploymentVersion,
+		Units:             int64(units[0]),
+	}
+	for _, u := range units[1:] {
+		if u != units[0] {
+			scale.Units = -1
+		}
+	}
+	return scale, nil
+}
+
+func (c *Client) ScaleApp(ctx context.Context, input *AppScale) error {
+	app, err := c.getApp(ctx, input.App)
+	if err != nil {
+		return err
+	}
+
+	err = app.SetUnits(input.selector(), int(input.Units))
+	if err != nil {
+		return err
+	}
+	return c.kube.Update(ctx, app)
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestGetAppScale(t *testing.T) {
+	units := 2
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{
+					Version: 1,
+					Processes: []v1beta1.ProcessSpec{
+						{Name: "web"},
+						{Name: "worker", Units: &units},
+					},
+				},
+			},
+		},
+	}
+	c := newFakeClient(t, app)
+
+	tests := []struct {
+		name    string
+		scale   *AppScale
+		want    int64
+		wantErr error
+	}{
+		{
+			name:  "process",
+			scale: &AppScale{App: "testapp", Process: "worker"},
+			want:  2,
+		},
+		{
+			name:  "processes with different units",
+			scale: &AppScale{App: "testapp", DeploymentVersion: 1},
+			want:  -1,
+		},
+		{
+			name:    "removed deployment",
+			scale:   &AppScale{App: "testapp", DeploymentVersion: 2},
+			wantErr: ErrDeploymentNotFound,
+		},
+		{
+			name:    "removed process",
+			scale:   &AppScale{App: "testapp", Process: "cron"},
+			wantErr: ErrProcessNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scale, err := c.GetAppScale(context.Background(), tt.scale)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, scale.Units)
+		})
+	}
+}
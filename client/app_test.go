@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestConvertToKetchAppWithBuilder(t *testing.T) {
+	app := &App{
+		Name:        "testapp",
+		Image:       "registry.example.com/testapp",
+		Framework:   "testfw",
+		Description: "test application",
+		AppVersion:  "1.2.0",
+		Builder:     "paketobuildpacks/builder:full",
+		BuildPacks:  []string{"paketo-buildpacks/go"},
+		Units:       2,
+		Processes: []*ProcessParameters{
+			{Name: "web", Cmd: []string{"./web"}},
+			{Name: "worker", Cmd: []string{"./worker"}, Units: 1},
+		},
+	}
+
+	ketchApp, err := app.convertToKetchApp()
+	require.NoError(t, err)
+	require.Equal(t, "paketobuildpacks/builder:full", ketchApp.Spec.Builder)
+	require.Equal(t, []string{"paketo-buildpacks/go"}, ketchApp.Spec.BuildPacks)
+	require.Equal(t, "test application", ketchApp.Spec.Description)
+	require.Equal(t, "1.2.0", *ketchApp.Spec.Version)
+	require.Len(t, ketchApp.Spec.Deployments, 1)
+
+	deployment := ketchApp.Spec.Deployments[0]
+	require.Equal(t, []v1beta1.ExposedPort{{Port: 8000, Protocol: "TCP"}}, deployment.ExposedPorts)
+	require.Equal(t, 2, *deployment.Processes[0].Units)
+	require.Equal(t, 1, *deployment.Processes[1].Units)
+	require.Equal(t, 3, ketchApp.Units())
+
+	converted := NewApp(ketchApp)
+	require.Equal(t, "paketobuildpacks/builder:full", converted.Builder)
+	require.Equal(t, "test application", converted.Description)
+	require.Equal(t, "1.2.0", converted.AppVersion)
+	require.Equal(t, int64(3), converted.TotalUnits)
+	require.Equal(t, AppStateRunning, converted.State)
+}
+
+func TestConvertToKetchAppUnits(t *testing.T) {
+	app := &App{
+		Name:      "testapp",
+		Image:     "registry.example.com/testapp",
+		Framework: "testfw",
+		Builder:   "paketobuildpacks/builder:full",
+		Units:     4,
+		Processes: []*ProcessParameters{
+			{Name: "web", Cmd: []string{"./web"}},
+			{Name: "worker", Cmd: []string{"./worker"}, Units: 2},
+		},
+	}
+
+	ketchApp, err := app.convertToKetchApp()
+	require.NoError(t, err)
+
+	// processes run different units, so the app has no common units
+	converted := NewApp(ketchApp)
+	require.Equal(t, int64(0), converted.Units)
+	require.Equal(t, int64(6), converted.TotalUnits)
+	require.Len(t, converted.Processes, 2)
+	require.Equal(t, int64(4), converted.Processes[0].Units)
+	require.Equal(t, int64(2), converted.Processes[1].Units)
+}
+
+func TestGetAppURLs(t *testing.T) {
+	framework := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec: v1beta1.FrameworkSpec{
+			Name:          "testfw",
+			NamespaceName: "ketch-testfw",
+			IngressController: v1beta1.IngressControllerSpec{
+				ServiceEndpoint: "10.0.0.1",
+				ClusterIssuer:   "letsencrypt",
+			},
+		},
+	}
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Framework: "testfw",
+			Ingress: v1beta1.IngressSpec{
+				GenerateDefaultCname: true,
+				Cnames:               v1beta1.CnameList{"testapp.example.com"},
+			},
+		},
+	}
+	c := newFakeClient(t, framework, app)
+
+	result, err := c.GetApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.True(t, result.GenerateDefaultCname)
+	require.Equal(t, "testapp.10.0.0.1.shipa.cloud", result.DefaultCname)
+	require.Equal(t, []string{
+		"http://testapp.10.0.0.1.shipa.cloud",
+		"https://testapp.example.com",
+	}, result.URLs)
+
+	// urls are unknown without the framework
+	c = newFakeClient(t, app)
+	result, err = c.GetApp(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Empty(t, result.DefaultCname)
+	require.Empty(t, result.URLs)
+}
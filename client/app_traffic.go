@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+// AppTraffic defines how incoming traffic of an app is split between its deployments.
+type AppTraffic struct {
+	App         string              `json:"app"`
+	Deployments []*DeploymentWeight `json:"deployment,omitempty"`
+}
+
+// DeploymentWeight defines a weight of a deployment used to route incoming traffic.
+type DeploymentWeight struct {
+	Version int64 `json:"version"`
+	Weight  int64 `json:"weight"`
+}
+
+// Validate checks that deployments are unique and their weights total 100.
+func (t *AppTraffic) Validate() error {
+	deployments := make([]*Deployment, 0, len(t.Deployments))
+	for _, d := range t.Deployments {
+		deployments = append(deployments, &Deployment{Version: d.Version, Weight: d.Weight})
+	}
+	return ValidateDeployments(deployments)
+}
+
+func (c *Client) GetAppTraffic(ctx context.Context, name string) (*AppTraffic, error) {
+	app, err := c.getApp(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	traffic := &AppTraffic{App: name}
+	for _, deployment := range app.Spec.Deployments {
+		traffic.Deployments = append(traffic.Deployments, &DeploymentWeight{
+			Version: int64(deployment.Version),
+			Weight:  int64(deployment.RoutingSettings.Weight),
+		})
+	}
+	return traffic, nil
+}
+
+// SetAppTraffic sets weights of the listed deployments of the app, deployments which are not listed get no traffic.
+func (c *Client) SetAppTraffic(ctx context.Context, input *AppTraffic) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	app, err := c.getApp(ctx, input.App)
+	if err != nil {
+		return err
+	}
+
+	weights := make(map[v1beta1.DeploymentVersion]uint8, len(input.Deployments))
+	for _, d := range input.Deployments {
+		weights[v1beta1.DeploymentVersion(d.Version)] = uint8(d.Weight)
+	}
+	for i, deployment := range app.Spec.Deployments {
+		app.Spec.Deployments[i].RoutingSettings.Weight = weights[deployment.Version]
+		delete(weights, deployment.Version)
+	}
+	for version := range weights {
+		return fmt.Errorf("deployment version %s: %w", version, v1beta1.ErrDeploymentNotFound)
+	}
+
+	return c.kube.Update(ctx, app)
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestSetAppTraffic(t *testing.T) {
+	app := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "testapp"},
+		Spec: v1beta1.AppSpec{
+			Deployments: []v1beta1.AppDeploymentSpec{
+				{Version: 1, RoutingSettings: v1beta1.RoutingSettings{Weight: 100}},
+				{Version: 2, RoutingSettings: v1beta1.RoutingSettings{Weight: 0}},
+			},
+		},
+	}
+	c := newFakeClient(t, app)
+
+	err := c.SetAppTraffic(context.Background(), &AppTraffic{
+		App: "testapp",
+		Deployments: []*DeploymentWeight{
+			{Version: 1, Weight: 60},
+			{Version: 2, Weight: 40},
+		},
+	})
+	require.NoError(t, err)
+
+	traffic, err := c.GetAppTraffic(context.Background(), "testapp")
+	require.NoError(t, err)
+	require.Equal(t, &AppTraffic{
+		App: "testapp",
+		Deployments: []*DeploymentWeight{
+			{Version: 1, Weight: 60},
+			{Version: 2, Weight: 40},
+		},
+	}, traffic)
+
+	err = c.SetAppTraffic(context.Background(), &AppTraffic{
+		App: "testapp",
+		Deployments: []*DeploymentWeight{
+			{Version: 1, Weight: 50},
+			{Version: 3, Weight: 50},
+		},
+	})
+	require.True(t, errors.Is(err, v1beta1.ErrDeploymentNotFound))
+
+	err = c.SetAppTraffic(context.Background(), &AppTraffic{
+		App: "testapp",
+		Deployments: []*DeploymentWeight{
+			{Version: 1, Weight: 50},
+		},
+	})
+	require.Error(t, err)
+}
@@ -2,19 +2,26 @@ package client
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// pollInterval is an interval between checks while waiting for ketch controller.
+const pollInterval = 5 * time.Second
+
 type Client struct {
-	kube client.Client
+	kube     client.Client
+	registry *Registry
 }
 
-func NewClient() (*Client, error) {
+func NewClient(registry *Registry) (*Client, error) {
 
 	var kubeconfig string
 	if home := homedir.HomeDir(); home != "" {
@@ -24,7 +31,7 @@ func NewClient() (*Client, error) {
 	// create the config object from kubeconfig
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 
-	schema, err := v1beta1.SchemeBuilder.Build()
+	schema, err := newScheme()
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +44,20 @@ func NewClient() (*Client, error) {
 	}
 
 	return &Client{
-		kube: kube,
+		kube:     kube,
+		registry: registry,
 	}, nil
 }
+
+// newScheme returns a scheme with ketch types and kubernetes built-in types.
+func newScheme() (*runtime.Scheme, error) {
+	scheme, err := v1beta1.SchemeBuilder.Build()
+	if err != nil {
+		return nil, err
+	}
+	err = clientgoscheme.AddToScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeClient(t *testing.T, objects ...client.Object) *Client {
+	scheme, err := newScheme()
+	require.NoError(t, err)
+
+	return &Client{
+		kube: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+	}
+}
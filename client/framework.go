@@ -2,17 +2,49 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"log"
+	"strings"
+	"time"
 )
 
 type Framework struct {
-	Name              string                 `json:"name"`
-	Namespace         string                 `json:"namespace"`
-	AppQuotaLimit     int64                  `json:"app_quota_limit,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// AppQuotaLimit is a number of apps the framework may run, nil means unlimited apps.
+	// The quota is not mapped by the helper which can't tell an unset quota from a zero one.
+	AppQuotaLimit *int64 `json:"-"`
+	// +optional
+	UnlimitedApps     bool                   `json:"unlimited_apps"`
 	IngressController *IngressControllerSpec `json:"ingress_controller"`
+	// +optional
+	NamespaceLabels map[string]string `json:"namespace_labels,omitempty"`
+	// +optional
+	NamespaceAnnotations map[string]string `json:"namespace_annotations,omitempty"`
+	// +readonly
+	NamespaceAdopted bool `json:"namespace_adopted"`
+	// +readonly
+	Phase string `json:"phase"`
+	// +readonly
+	Message string `json:"message"`
+	// +readonly
+	NamespaceUID string `json:"namespace_uid"`
+	// +readonly
+	Apps []string `json:"apps"`
+	// +readonly
+	Jobs []string `json:"jobs"`
+	// +readonly
+	AppsRemaining int64 `json:"apps_remaining"`
+
+	// RemovedNamespaceLabels and RemovedNamespaceAnnotations hold keys removed from the configuration since the last update.
+	RemovedNamespaceLabels      []string `json:"-"`
+	RemovedNamespaceAnnotations []string `json:"-"`
 }
 
 // IngressControllerSpec contains configuration for an ingress controller.
@@ -24,36 +56,62 @@ type IngressControllerSpec struct {
 }
 
 func NewFramework(input *v1beta1.Framework) *Framework {
-	var appQuotaLimit int64
-	if input.Spec.AppQuotaLimit != nil {
-		appQuotaLimit = int64(*input.Spec.AppQuotaLimit)
+	// a negative quota means unlimited apps for ketch controller
+	var appQuotaLimit *int64
+	var appsRemaining int64 = -1
+	if input.Spec.AppQuotaLimit != nil && *input.Spec.AppQuotaLimit >= 0 {
+		quota := int64(*input.Spec.AppQuotaLimit)
+		appQuotaLimit = &quota
+		appsRemaining = quota - int64(len(input.Status.Apps))
+		if appsRemaining < 0 {
+			appsRemaining = 0
+		}
+	}
+
+	var namespaceUID string
+	if input.Status.Namespace != nil {
+		namespaceUID = string(input.Status.Namespace.UID)
 	}
 
 	return &Framework{
 		Name:          input.Spec.Name,
 		Namespace:     input.Spec.NamespaceName,
 		AppQuotaLimit: appQuotaLimit,
+		UnlimitedApps: appQuotaLimit == nil,
 		IngressController: &IngressControllerSpec{
 			ClassName:       input.Spec.IngressController.ClassName,
 			ServiceEndpoint: input.Spec.IngressController.ServiceEndpoint,
 			ClusterIssuer:   input.Spec.IngressController.ClusterIssuer,
 			IngressType:     input.Spec.IngressController.IngressType.String(),
 		},
+		Phase:         string(input.Status.Phase),
+		Message:       input.Status.Message,
+		NamespaceUID:  namespaceUID,
+		Apps:          input.Status.Apps,
+		Jobs:          input.Status.Jobs,
+		AppsRemaining: appsRemaining,
 	}
 }
 
-func (f *Framework) convertToKetchFramework() *v1beta1.Framework {
-	namespace := f.Namespace
-	if namespace == "" {
-		// default value
-		namespace = "ketch-" + f.Name
+// namespaceName returns a namespace of the framework, "ketch-<name>" is used by default.
+func (f *Framework) namespaceName() string {
+	if f.Namespace == "" {
+		return "ketch-" + f.Name
 	}
+	return f.Namespace
+}
 
-	// default value
-	appQuotaLimit := -1
-	if f.AppQuotaLimit != 0 {
-		appQuotaLimit = int(f.AppQuotaLimit)
+// appQuotaLimit returns a limit of apps of the framework, -1 means unlimited.
+func (f *Framework) appQuotaLimit() int {
+	if f.UnlimitedApps || f.AppQuotaLimit == nil {
+		return -1
 	}
+	return int(*f.AppQuotaLimit)
+}
+
+func (f *Framework) convertToKetchFramework() *v1beta1.Framework {
+	namespace := f.namespaceName()
+	appQuotaLimit := f.appQuotaLimit()
 
 	return &v1beta1.Framework{
 		ObjectMeta: metav1.ObjectMeta{
@@ -79,7 +137,16 @@ func (c *Client) GetFramework(ctx context.Context, name string) (*Framework, err
 		return nil, err
 	}
 
-	return NewFramework(framework), nil
+	result := NewFramework(framework)
+	namespace, err := c.getNamespace(ctx, framework.Spec.NamespaceName)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+	if namespace != nil {
+		result.NamespaceLabels = namespace.Labels
+		result.NamespaceAnnotations = namespace.Annotations
+	}
+	return result, nil
 }
 
 func (c *Client) DeleteFramework(ctx context.Context, name string) error {
@@ -107,8 +174,14 @@ func (c *Client) getFramework(ctx context.Context, name string) (*v1beta1.Framew
 	return framework, nil
 }
 
+// CreateFramework creates the framework, an existing namespace is adopted by the framework.
 func (c *Client) CreateFramework(ctx context.Context, input *Framework) error {
 	framework := input.convertToKetchFramework()
+	adopted, err := c.namespaceExists(ctx, framework.Spec.NamespaceName)
+	if err != nil {
+		return err
+	}
+	input.NamespaceAdopted = adopted
 	return c.kube.Create(ctx, framework)
 }
 
@@ -122,3 +195,83 @@ func (c *Client) UpdateFramework(ctx context.Context, input *Framework) error {
 	framework.Spec = updates.Spec
 	return c.kube.Update(ctx, framework)
 }
+
+// ValidateFramework checks the framework with the rules of the framework admission webhook,
+// so problems are reported before the framework is created or updated.
+// The current framework is empty for a new framework, a renamed framework is replaced and frees its namespace.
+func (c *Client) ValidateFramework(ctx context.Context, input *Framework, current string) error {
+	namespace := input.namespaceName()
+	framework, err := c.getFramework(ctx, input.Name)
+	if k8serrors.IsNotFound(err) {
+		return c.checkNamespaceIsFree(ctx, namespace, input.Name, current)
+	}
+	if err != nil {
+		return err
+	}
+
+	if framework.Spec.NamespaceName != namespace {
+		apps, err := c.frameworkApps(ctx, framework)
+		if err != nil {
+			return err
+		}
+		if len(apps) > 0 {
+			return fmt.Errorf("%w: %s", v1beta1.ErrChangeNamespaceWhenAppsRunning, strings.Join(apps, ", "))
+		}
+		if err := c.checkNamespaceIsFree(ctx, namespace, input.Name, current); err != nil {
+			return err
+		}
+	}
+
+	currentQuotaLimit := -1
+	if framework.Spec.AppQuotaLimit != nil {
+		currentQuotaLimit = *framework.Spec.AppQuotaLimit
+	}
+	appQuotaLimit := input.appQuotaLimit()
+	if currentQuotaLimit != appQuotaLimit {
+		if appQuotaLimit != -1 && appQuotaLimit < len(framework.Status.Apps) {
+			return v1beta1.ErrDecreaseQuota
+		}
+	}
+	return nil
+}
+
+// checkNamespaceIsFree checks that the namespace is not used by frameworks other than the named ones.
+func (c *Client) checkNamespaceIsFree(ctx context.Context, namespace string, names ...string) error {
+	frameworks := &v1beta1.FrameworkList{}
+	if err := c.kube.List(ctx, frameworks); err != nil {
+		return err
+	}
+	for _, framework := range frameworks.Items {
+		if framework.Spec.NamespaceName != namespace {
+			continue
+		}
+		named := false
+		for _, name := range names {
+			named = named || framework.Name == name
+		}
+		if !named {
+			return v1beta1.ErrNamespaceIsUsedByAnotherFramework
+		}
+	}
+	return nil
+}
+
+// WaitForFramework waits until ketch controller creates a namespace of the framework.
+func (c *Client) WaitForFramework(ctx context.Context, name string, timeout time.Duration) error {
+	var phase v1beta1.FrameworkPhase
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		framework, err := c.getFramework(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		phase = framework.Status.Phase
+		if phase == v1beta1.FrameworkFailed {
+			return false, fmt.Errorf("framework %q failed: %s", name, framework.Status.Message)
+		}
+		return phase == v1beta1.FrameworkCreated && framework.Status.Namespace != nil, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timeout waiting for framework %q to be ready, phase is %q", name, phase)
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+const (
+	// FrameworkDeletionBlock refuses to delete a framework with apps.
+	FrameworkDeletionBlock = "block"
+	// FrameworkDeletionWait waits until apps of a framework are deleted by someone else.
+	FrameworkDeletionWait = "wait"
+	// FrameworkDeletionCascade deletes apps and jobs of a framework before the framework.
+	FrameworkDeletionCascade = "cascade"
+)
+
+// FrameworkDeletionPolicies lists supported policies of a framework deletion.
+var FrameworkDeletionPolicies = []string{FrameworkDeletionBlock, FrameworkDeletionWait, FrameworkDeletionCascade}
+
+// DeleteFrameworkWithPolicy deletes the framework handling its apps according to the policy,
+// and waits until the framework is removed. A namespace adopted by the framework is not waited for,
+// it existed before the framework and may outlive it.
+func (c *Client) DeleteFrameworkWithPolicy(ctx context.Context, name string, policy string, namespaceAdopted bool, timeout time.Duration) error {
+	framework, err := c.getFramework(ctx, name)
+	if err != nil {
+		return err
+	}
+	apps, err := c.frameworkApps(ctx, framework)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	if len(apps) > 0 {
+		switch policy {
+		case FrameworkDeletionWait:
+		case FrameworkDeletionCascade:
+			if err := c.deleteFrameworkWorkloads(ctx, name, apps); err != nil {
+				return err
+			}
+		default:
+			return errFrameworkHasApps(apps)
+		}
+		if err := c.waitForFrameworkApps(ctx, name, time.Until(deadline)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.DeleteFramework(ctx, name); err != nil {
+		return err
+	}
+	if namespaceAdopted {
+		framework.Status.Namespace = nil
+	}
+	return c.waitForFrameworkRemoval(ctx, framework, time.Until(deadline))
+}
+
+// CheckFrameworkDeletion checks that the framework can be deleted right away when its deletion is blocked by apps.
+func (c *Client) CheckFrameworkDeletion(ctx context.Context, name string, policy string) error {
+	if policy != FrameworkDeletionBlock {
+		return nil
+	}
+	framework, err := c.getFramework(ctx, name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	apps, err := c.frameworkApps(ctx, framework)
+	if err != nil {
+		return err
+	}
+	if len(apps) > 0 {
+		return errFrameworkHasApps(apps)
+	}
+	return nil
+}
+
+func errFrameworkHasApps(apps []string) error {
+	return fmt.Errorf("%w: %s", v1beta1.ErrDeleteFrameworkWithRunningApps, strings.Join(apps, ", "))
+}
+
+// frameworkApps returns names of apps of the framework, both known by the framework status and running in the framework.
+func (c *Client) frameworkApps(ctx context.Context, framework *v1beta1.Framework) ([]string, error) {
+	names := make(map[string]struct{}, len(framework.Status.Apps))
+	for _, app := range framework.Status.Apps {
+		names[app] = struct{}{}
+	}
+
+	apps := &v1beta1.AppList{}
+	if err := c.kube.List(ctx, apps); err != nil {
+		return nil, err
+	}
+	for _, app := range apps.Items {
+		if app.Spec.Framework == framework.Name {
+			names[app.Name] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// deleteFrameworkWorkloads deletes apps and jobs of the framework.
+func (c *Client) deleteFrameworkWorkloads(ctx context.Context, name string, apps []string) error {
+	for _, app := range apps {
+		if err := c.DeleteApp(ctx, app); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	jobs := &v1beta1.JobList{}
+	if err := c.kube.List(ctx, jobs); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if job.Spec.Framework != name {
+			continue
+		}
+		if err := c.kube.Delete(ctx, job); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForFrameworkApps waits until the framework has no apps.
+func (c *Client) waitForFrameworkApps(ctx context.Context, name string, timeout time.Duration) error {
+	var apps []string
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		framework, err := c.getFramework(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		apps, err = c.frameworkApps(ctx, framework)
+		if err != nil {
+			return false, err
+		}
+		return len(apps) == 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timeout waiting for apps of framework %q to be deleted: %s", name, strings.Join(apps, ", "))
+	}
+	return err
+}
+
+// waitForFrameworkRemoval waits until the framework and its namespace are removed.
+func (c *Client) waitForFrameworkRemoval(ctx context.Context, framework *v1beta1.Framework, timeout time.Duration) error {
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		_, err := c.getFramework(ctx, framework.Name)
+		if err == nil {
+			return false, nil
+		}
+		if !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+		if framework.Status.Namespace == nil {
+			return true, nil
+		}
+		err = c.kube.Get(ctx, types.NamespacedName{Name: framework.Status.Namespace.Name}, &corev1.Namespace{})
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timeout waiting for framework %q and its namespace to be removed", framework.Name)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestDeleteFrameworkWithPolicy(t *testing.T) {
+	newFramework := func() *v1beta1.Framework {
+		return &v1beta1.Framework{
+			ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+			Spec:       v1beta1.FrameworkSpec{Name: "testfw", NamespaceName: "ketch-testfw"},
+			Status: v1beta1.FrameworkStatus{
+				Namespace: &corev1.ObjectReference{Name: "ketch-testfw"},
+			},
+		}
+	}
+	newApp := func(name string) *v1beta1.App {
+		return &v1beta1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1beta1.AppSpec{Framework: "testfw"},
+		}
+	}
+	job := &v1beta1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "testjob"},
+		Spec:       v1beta1.JobSpec{Name: "testjob", Framework: "testfw"},
+	}
+	otherApp := &v1beta1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "otherapp"},
+		Spec:       v1beta1.AppSpec{Framework: "otherfw"},
+	}
+
+	t.Run("block", func(t *testing.T) {
+		c := newFakeClient(t, newFramework(), newApp("app2"), newApp("app1"), otherApp)
+
+		err := c.CheckFrameworkDeletion(context.Background(), "testfw", FrameworkDeletionBlock)
+		require.True(t, errors.Is(err, v1beta1.ErrDeleteFrameworkWithRunningApps))
+		require.EqualError(t, err, "failed to delete framework because the framework contains running apps: app1, app2")
+		require.NoError(t, c.CheckFrameworkDeletion(context.Background(), "testfw", FrameworkDeletionCascade))
+
+		err = c.DeleteFrameworkWithPolicy(context.Background(), "testfw", FrameworkDeletionBlock, false, time.Minute)
+		require.True(t, errors.Is(err, v1beta1.ErrDeleteFrameworkWithRunningApps))
+		_, err = c.getFramework(context.Background(), "testfw")
+		require.NoError(t, err)
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		c := newFakeClient(t, newFramework(), newApp("app1"))
+
+		err := c.DeleteFrameworkWithPolicy(context.Background(), "testfw", FrameworkDeletionWait, false, time.Millisecond)
+		require.EqualError(t, err, `timeout waiting for apps of framework "testfw" to be deleted: app1`)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		c := newFakeClient(t, newFramework(), newApp("app1"), job, otherApp)
+
+		err := c.DeleteFrameworkWithPolicy(context.Background(), "testfw", FrameworkDeletionCascade, false, time.Minute)
+		require.NoError(t, err)
+
+		_, err = c.getFramework(context.Background(), "testfw")
+		require.True(t, k8serrors.IsNotFound(err))
+		_, err = c.getApp(context.Background(), "app1")
+		require.True(t, k8serrors.IsNotFound(err))
+		_, err = c.getJob(context.Background(), "testjob")
+		require.True(t, k8serrors.IsNotFound(err))
+		_, err = c.getApp(context.Background(), "otherapp")
+		require.NoError(t, err)
+	})
+
+	t.Run("namespace is not removed", func(t *testing.T) {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ketch-testfw"}}
+		c := newFakeClient(t, newFramework(), namespace)
+
+		err := c.DeleteFrameworkWithPolicy(context.Background(), "testfw", FrameworkDeletionBlock, false, time.Millisecond)
+		require.EqualError(t, err, `timeout waiting for framework "testfw" and its namespace to be removed`)
+		require.NoError(t, c.kube.Get(context.Background(), types.NamespacedName{Name: "ketch-testfw"}, &corev1.Namespace{}))
+	})
+
+	t.Run("adopted namespace is kept", func(t *testing.T) {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ketch-testfw"}}
+		c := newFakeClient(t, newFramework(), namespace)
+
+		err := c.DeleteFrameworkWithPolicy(context.Background(), "testfw", FrameworkDeletionBlock, true, time.Minute)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func (c *Client) getNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	namespace := &corev1.Namespace{}
+	err := c.kube.Get(ctx, types.NamespacedName{Name: name}, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return namespace, nil
+}
+
+// namespaceExists checks if the namespace exists, e.g. to be adopted by a new framework.
+func (c *Client) namespaceExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.getNamespace(ctx, name)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ApplyFrameworkNamespace sets labels and annotations of the framework namespace keeping labels and annotations added by others.
+// A namespace created by ketch controller is waited for.
+func (c *Client) ApplyFrameworkNamespace(ctx context.Context, input *Framework, timeout time.Duration) error {
+	if len(input.NamespaceLabels) == 0 && len(input.NamespaceAnnotations) == 0 &&
+		len(input.RemovedNamespaceLabels) == 0 && len(input.RemovedNamespaceAnnotations) == 0 {
+		return nil
+	}
+
+	name := input.namespaceName()
+	var namespace *corev1.Namespace
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		var err error
+		namespace, err = c.getNamespace(ctx, name)
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timeout waiting for namespace %q of framework %q", name, input.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	namespace.Labels = mergeMetadata(namespace.Labels, input.NamespaceLabels, input.RemovedNamespaceLabels)
+	namespace.Annotations = mergeMetadata(namespace.Annotations, input.NamespaceAnnotations, input.RemovedNamespaceAnnotations)
+	return c.kube.Update(ctx, namespace)
+}
@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestNewFramework(t *testing.T) {
+	quota := 3
+	framework := NewFramework(&v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec: v1beta1.FrameworkSpec{
+			Name:          "testfw",
+			NamespaceName: "ketch-testfw",
+			AppQuotaLimit: &quota,
+			IngressController: v1beta1.IngressControllerSpec{
+				ClassName:   "traefik",
+				IngressType: v1beta1.TraefikIngressControllerType,
+			},
+		},
+		Status: v1beta1.FrameworkStatus{
+			Phase:     v1beta1.FrameworkCreated,
+			Message:   "framework created",
+			Namespace: &corev1.ObjectReference{Name: "ketch-testfw", UID: "5d2b6d9a"},
+			Apps:      []string{"testapp"},
+			Jobs:      []string{"testjob"},
+		},
+	})
+
+	appQuotaLimit := int64(3)
+	require.Equal(t, &Framework{
+		Name:          "testfw",
+		Namespace:     "ketch-testfw",
+		AppQuotaLimit: &appQuotaLimit,
+		IngressController: &IngressControllerSpec{
+			ClassName:   "traefik",
+			IngressType: "traefik",
+		},
+		Phase:         "Created",
+		Message:       "framework created",
+		NamespaceUID:  "5d2b6d9a",
+		Apps:          []string{"testapp"},
+		Jobs:          []string{"testjob"},
+		AppsRemaining: 2,
+	}, framework)
+
+	unlimited := -1
+	framework = NewFramework(&v1beta1.Framework{
+		Spec:   v1beta1.FrameworkSpec{Name: "testfw", AppQuotaLimit: &unlimited},
+		Status: v1beta1.FrameworkStatus{Apps: []string{"testapp"}},
+	})
+	require.Nil(t, framework.AppQuotaLimit)
+	require.True(t, framework.UnlimitedApps)
+	require.Equal(t, int64(-1), framework.AppsRemaining)
+	require.Equal(t, -1, framework.appQuotaLimit())
+}
+
+func TestWaitForFramework(t *testing.T) {
+	ready := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready"},
+		Spec:       v1beta1.FrameworkSpec{Name: "ready", NamespaceName: "ketch-ready"},
+		Status: v1beta1.FrameworkStatus{
+			Phase:     v1beta1.FrameworkCreated,
+			Namespace: &corev1.ObjectReference{Name: "ketch-ready"},
+		},
+	}
+	failed := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed"},
+		Spec:       v1beta1.FrameworkSpec{Name: "failed", NamespaceName: "ketch-failed"},
+		Status: v1beta1.FrameworkStatus{
+			Phase:   v1beta1.FrameworkFailed,
+			Message: "namespace is used by another framework",
+		},
+	}
+	pending := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending"},
+		Spec:       v1beta1.FrameworkSpec{Name: "pending", NamespaceName: "ketch-pending"},
+	}
+	c := newFakeClient(t, ready, failed, pending)
+
+	require.NoError(t, c.WaitForFramework(context.Background(), "ready", time.Minute))
+
+	err := c.WaitForFramework(context.Background(), "failed", time.Minute)
+	require.EqualError(t, err, `framework "failed" failed: namespace is used by another framework`)
+
+	err = c.WaitForFramework(context.Background(), "pending", time.Millisecond)
+	require.EqualError(t, err, `timeout waiting for framework "pending" to be ready, phase is ""`)
+}
+
+func TestValidateFramework(t *testing.T) {
+	quota := 2
+	framework := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec: v1beta1.FrameworkSpec{
+			Name:          "testfw",
+			NamespaceName: "ketch-testfw",
+			AppQuotaLimit: &quota,
+		},
+		Status: v1beta1.FrameworkStatus{
+			Apps: []string{"app1", "app2"},
+		},
+	}
+	empty := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "emptyfw"},
+		Spec:       v1beta1.FrameworkSpec{Name: "emptyfw", NamespaceName: "ketch-emptyfw"},
+	}
+	c := newFakeClient(t, framework, empty)
+
+	tests := []struct {
+		name      string
+		framework *Framework
+		current   string
+		wantErr   error
+	}{
+		{
+			name:      "new framework",
+			framework: &Framework{Name: "newfw"},
+		},
+		{
+			name:      "new framework in a used namespace",
+			framework: &Framework{Name: "newfw", Namespace: "ketch-testfw"},
+			wantErr:   v1beta1.ErrNamespaceIsUsedByAnotherFramework,
+		},
+		{
+			name:      "renamed framework in its namespace",
+			framework: &Framework{Name: "renamedfw", Namespace: "ketch-testfw", AppQuotaLimit: appQuota(3)},
+			current:   "testfw",
+		},
+		{
+			name:      "unchanged framework",
+			framework: &Framework{Name: "testfw", AppQuotaLimit: appQuota(2)},
+		},
+		{
+			name:      "namespace change with running apps",
+			framework: &Framework{Name: "testfw", Namespace: "testns", AppQuotaLimit: appQuota(2)},
+			wantErr:   v1beta1.ErrChangeNamespaceWhenAppsRunning,
+		},
+		{
+			name:      "namespace change to a used namespace",
+			framework: &Framework{Name: "emptyfw", Namespace: "ketch-testfw"},
+			wantErr:   v1beta1.ErrNamespaceIsUsedByAnotherFramework,
+		},
+		{
+			name:      "namespace change without apps",
+			framework: &Framework{Name: "emptyfw", Namespace: "testns"},
+		},
+		{
+			name:      "quota decrease below running apps",
+			framework: &Framework{Name: "testfw", AppQuotaLimit: appQuota(1)},
+			wantErr:   v1beta1.ErrDecreaseQuota,
+		},
+		{
+			name:      "zero quota with running apps",
+			framework: &Framework{Name: "testfw", AppQuotaLimit: appQuota(0)},
+			wantErr:   v1beta1.ErrDecreaseQuota,
+		},
+		{
+			name:      "zero quota without apps",
+			framework: &Framework{Name: "emptyfw", AppQuotaLimit: appQuota(0)},
+		},
+		{
+			name:      "unlimited quota",
+			framework: &Framework{Name: "testfw"},
+		},
+		{
+			name:      "unlimited apps",
+			framework: &Framework{Name: "testfw", AppQuotaLimit: appQuota(1), UnlimitedApps: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.ValidateFramework(context.Background(), tt.framework, tt.current)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	err := c.ValidateFramework(context.Background(), &Framework{Name: "testfw", Namespace: "testns", AppQuotaLimit: appQuota(2)}, "testfw")
+	require.EqualError(t, err, "failed to change target namespace because the framework contains running apps: app1, app2")
+}
+
+func TestFrameworkNamespace(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "shared",
+			Labels: map[string]string{"kubernetes.io/metadata.name": "shared", "team": "payments"},
+		},
+	}
+	c := newFakeClient(t, namespace)
+
+	framework := &Framework{
+		Name:              "testfw",
+		Namespace:         "shared",
+		IngressController: &IngressControllerSpec{IngressType: "traefik"},
+		NamespaceLabels:   map[string]string{"istio-injection": "enabled"},
+	}
+	require.NoError(t, c.CreateFramework(context.Background(), framework))
+	require.True(t, framework.NamespaceAdopted)
+	require.NoError(t, c.ApplyFrameworkNamespace(context.Background(), framework, time.Minute))
+
+	result, err := c.GetFramework(context.Background(), "testfw")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"istio-injection":             "enabled",
+		"kubernetes.io/metadata.name": "shared",
+		"team":                        "payments",
+	}, result.NamespaceLabels)
+
+	framework.NamespaceLabels = nil
+	framework.RemovedNamespaceLabels = []string{"istio-injection"}
+	require.NoError(t, c.ApplyFrameworkNamespace(context.Background(), framework, time.Minute))
+	result, err = c.GetFramework(context.Background(), "testfw")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"kubernetes.io/metadata.name": "shared",
+		"team":                        "payments",
+	}, result.NamespaceLabels)
+
+	// a namespace created by ketch controller is waited for
+	other := &Framework{
+		Name:              "otherfw",
+		IngressController: &IngressControllerSpec{IngressType: "traefik"},
+		NamespaceLabels:   map[string]string{"istio-injection": "enabled"},
+	}
+	require.NoError(t, c.CreateFramework(context.Background(), other))
+	require.False(t, other.NamespaceAdopted)
+	err = c.ApplyFrameworkNamespace(context.Background(), other, time.Millisecond)
+	require.EqualError(t, err, `timeout waiting for namespace "ketch-otherfw" of framework "otherfw"`)
+}
+
+func appQuota(quota int64) *int64 {
+	return &quota
+}
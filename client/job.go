@@ -180,3 +180,18 @@ func (c *Client) DeleteJob(ctx context.Context, name string) error {
 
 	return nil
 }
+
+// ValidateJob checks that the job name is not used by jobs other than the current one, as the job admission webhook does.
+// The current job is empty for a new job.
+func (c *Client) ValidateJob(ctx context.Context, input *Job, current string) error {
+	jobs := &v1beta1.JobList{}
+	if err := c.kube.List(ctx, jobs); err != nil {
+		return err
+	}
+	for _, job := range jobs.Items {
+		if job.Spec.Name == input.Name && job.Name != current {
+			return v1beta1.ErrJobExists
+		}
+	}
+	return nil
+}
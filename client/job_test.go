@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestValidateJob(t *testing.T) {
+	job := &v1beta1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "testjob"},
+		Spec:       v1beta1.JobSpec{Name: "testjob", Framework: "testfw"},
+	}
+	c := newFakeClient(t, job)
+
+	require.NoError(t, c.ValidateJob(context.Background(), &Job{Name: "newjob"}, ""))
+	require.Equal(t, v1beta1.ErrJobExists, c.ValidateJob(context.Background(), &Job{Name: "testjob"}, ""))
+
+	// a job doesn't conflict with itself
+	require.NoError(t, c.ValidateJob(context.Background(), &Job{Name: "testjob"}, "testjob"))
+	require.Equal(t, v1beta1.ErrJobExists, c.ValidateJob(context.Background(), &Job{Name: "testjob"}, "otherjob"))
+}
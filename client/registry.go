@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Registry contains credentials of a docker registry used to create image pull secrets.
+type Registry struct {
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// +optional
+	Email string `json:"email"`
+}
+
+// DockerRegistry contains docker registry configuration of an app.
+type DockerRegistry struct {
+	SecretName string `json:"secret_name"`
+	// +optional
+	CreateSecret bool `json:"create_secret"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth"`
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+func (r *Registry) dockerConfigJSON() ([]byte, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(r.Username + ":" + r.Password))
+	return json.Marshal(dockerConfigJSON{
+		Auths: map[string]dockerConfigEntry{
+			r.Server: {
+				Username: r.Username,
+				Password: r.Password,
+				Email:    r.Email,
+				Auth:     auth,
+			},
+		},
+	})
+}
+
+func (c *Client) frameworkNamespace(ctx context.Context, name string) (string, error) {
+	framework, err := c.getFramework(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return framework.Spec.NamespaceName, nil
+}
+
+// ApplyRegistrySecret creates or updates an image pull secret with the provider registry credentials
+// in the namespace of the framework.
+func (c *Client) ApplyRegistrySecret(ctx context.Context, framework, name string) error {
+	if c.registry == nil {
+		return errors.New("registry credentials are not configured in the provider")
+	}
+
+	namespace, err := c.frameworkNamespace(ctx, framework)
+	if err != nil {
+		return err
+	}
+
+	config, err := c.registry.dockerConfigJSON()
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	err = c.kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret)
+	if k8serrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{
+				corev1.DockerConfigJsonKey: config,
+			},
+		}
+		return c.kube.Create(ctx, secret)
+	}
+	if err != nil {
+		return err
+	}
+
+	secret.Type = corev1.SecretTypeDockerConfigJson
+	secret.Data = map[string][]byte{
+		corev1.DockerConfigJsonKey: config,
+	}
+	return c.kube.Update(ctx, secret)
+}
+
+// RegistrySecretExists checks if an image pull secret exists in the namespace of the framework.
+func (c *Client) RegistrySecretExists(ctx context.Context, framework, name string) (bool, error) {
+	namespace, err := c.frameworkNamespace(ctx, framework)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = c.kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Secret{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteRegistrySecret deletes an image pull secret from the namespace of the framework,
+// the secret of a removed framework is removed with the namespace of the framework.
+func (c *Client) DeleteRegistrySecret(ctx context.Context, framework, name string) error {
+	namespace, err := c.frameworkNamespace(ctx, framework)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	err = c.kube.Delete(ctx, secret)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
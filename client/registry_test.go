@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/brunoa19/ketch-terraform-provider/client/v1beta1"
+)
+
+func TestRegistrySecret(t *testing.T) {
+	framework := &v1beta1.Framework{
+		ObjectMeta: metav1.ObjectMeta{Name: "testfw"},
+		Spec:       v1beta1.FrameworkSpec{Name: "testfw", NamespaceName: "ketch-testfw"},
+	}
+	c := newFakeClient(t, framework)
+
+	err := c.ApplyRegistrySecret(context.Background(), "testfw", "registry")
+	require.Error(t, err)
+
+	c.registry = &Registry{
+		Server:   "registry.example.com",
+		Username: "user",
+		Password: "secret",
+	}
+	err = c.ApplyRegistrySecret(context.Background(), "testfw", "registry")
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	err = c.kube.Get(context.Background(), types.NamespacedName{Namespace: "ketch-testfw", Name: "registry"}, secret)
+	require.NoError(t, err)
+	require.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+	require.JSONEq(t, `{"auths":{"registry.example.com":{"username":"user","password":"secret","auth":"dXNlcjpzZWNyZXQ="}}}`,
+		string(secret.Data[corev1.DockerConfigJsonKey]))
+
+	exists, err := c.RegistrySecretExists(context.Background(), "testfw", "registry")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	err = c.DeleteRegistrySecret(context.Background(), "testfw", "registry")
+	require.NoError(t, err)
+	err = c.DeleteRegistrySecret(context.Background(), "testfw", "registry")
+	require.NoError(t, err)
+
+	exists, err = c.RegistrySecretExists(context.Background(), "testfw", "registry")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// the secret of a removed framework is gone with the framework
+	err = c.DeleteRegistrySecret(context.Background(), "removed", "registry")
+	require.NoError(t, err)
+}
@@ -65,13 +65,16 @@ func (r *Job) ValidateUpdate(old runtime.Object) error {
 	if !ok {
 		return fmt.Errorf("can't validate job update")
 	}
+	if oldJob.Spec.Name == r.Spec.Name {
+		return nil
+	}
 	client := jobmgr.GetClient()
 	jobs := JobList{}
 	if err := client.List(context.Background(), &jobs); err != nil {
 		return err
 	}
 	for _, job := range jobs.Items {
-		if job.Spec.Name == oldJob.Spec.Name {
+		if job.Spec.Name == r.Spec.Name && job.Name != r.Name {
 			return ErrJobExists
 		}
 	}
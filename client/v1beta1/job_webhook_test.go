@@ -0,0 +1,71 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeManager struct {
+	client client.Client
+}
+
+func (m *fakeManager) GetClient() client.Client {
+	return m.client
+}
+
+func newJob(name string) *Job {
+	return &Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       JobSpec{Name: name, Framework: "testfw"},
+	}
+}
+
+func setupFakeJobManager(t *testing.T, objects ...client.Object) {
+	scheme, err := SchemeBuilder.Build()
+	require.NoError(t, err)
+
+	jobmgr = &fakeManager{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	t.Cleanup(func() { jobmgr = nil })
+}
+
+func TestJobValidateCreate(t *testing.T) {
+	setupFakeJobManager(t, newJob("testjob"))
+
+	require.NoError(t, newJob("newjob").ValidateCreate())
+	require.Equal(t, ErrJobExists, newJob("testjob").ValidateCreate())
+}
+
+func TestJobValidateUpdate(t *testing.T) {
+	setupFakeJobManager(t, newJob("testjob"), newJob("otherjob"))
+
+	job := newJob("testjob")
+	job.Spec.Description = "updated"
+	require.NoError(t, job.ValidateUpdate(newJob("testjob")))
+
+	renamed := newJob("testjob")
+	renamed.Spec.Name = "newjob"
+	require.NoError(t, renamed.ValidateUpdate(newJob("testjob")))
+
+	renamed.Spec.Name = "otherjob"
+	require.Equal(t, ErrJobExists, renamed.ValidateUpdate(newJob("testjob")))
+}
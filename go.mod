@@ -15,6 +15,7 @@ require (
 	github.com/opencontainers/runc v0.1.1 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.8.1 // indirect
+	github.com/stretchr/testify v1.7.0
 	google.golang.org/grpc v1.33.1 // indirect
 	k8s.io/api v0.21.3
 	k8s.io/apimachinery v0.21.3
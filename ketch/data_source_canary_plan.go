@@ -0,0 +1,80 @@
+package ketch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+	"github.com/brunoa19/ketch-terraform-provider/helper"
+)
+
+func dataSourceCanaryPlan() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceCanaryPlanRead,
+		Schema: map[string]*schema.Schema{
+			"steps": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(1, 100),
+			},
+			"step_weight": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntBetween(1, 100),
+			},
+			"step_interval": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateDuration,
+			},
+			"start_time": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"schedule": canaryScheduleSchema,
+		},
+	}
+}
+
+func extractCanary(d *schema.ResourceData) *client.Canary {
+	raw := d.Get("")
+	var canary client.Canary
+	helper.TerraformToStruct(raw, &canary)
+	return &canary
+}
+
+func dataSourceCanaryPlanRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	canary := extractCanary(d)
+
+	start := time.Now()
+	if v, ok := d.GetOk("start_time"); ok {
+		var err error
+		start, err = time.Parse(time.RFC3339, v.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	schedule, err := canary.Schedule(start)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set("schedule", helper.StructToTerraform(&schedule))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%d:%d:%s:%s", canary.Steps, canary.StepWeight, canary.StepInterval, start.UTC().Format(time.RFC3339)))
+
+	return diags
+}
@@ -0,0 +1,38 @@
+package ketch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceCanaryPlanRead(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSourceCanaryPlan().Schema, map[string]interface{}{
+		"steps":         2,
+		"step_weight":   50,
+		"step_interval": "1h",
+		"start_time":    "2021-08-01T10:00:00Z",
+	})
+
+	diags := dataSourceCanaryPlanRead(context.Background(), d, nil)
+	require.False(t, diags.HasError())
+	require.Equal(t, "2:50:1h:2021-08-01T10:00:00Z", d.Id())
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"step":           1,
+			"time":           "2021-08-01T11:00:00Z",
+			"primary_weight": 50,
+			"canary_weight":  50,
+			"promoted":       false,
+		},
+		map[string]interface{}{
+			"step":           2,
+			"time":           "2021-08-01T12:00:00Z",
+			"primary_weight": 0,
+			"canary_weight":  100,
+			"promoted":       true,
+		},
+	}, d.Get("schedule"))
+}
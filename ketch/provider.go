@@ -3,16 +3,56 @@ package ketch
 import (
 	"context"
 	"github.com/brunoa19/ketch-terraform-provider/client"
+	"github.com/brunoa19/ketch-terraform-provider/helper"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+var (
+	schemaRegistry = &schema.Schema{
+		Type:     schema.TypeList,
+		MaxItems: 1,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"server": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"username": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"password": {
+					Type:      schema.TypeString,
+					Required:  true,
+					Sensitive: true,
+				},
+				"email": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			},
+		},
+	}
+)
+
 func Provider() *schema.Provider {
 	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"registry": schemaRegistry,
+		},
 		ResourcesMap: map[string]*schema.Resource{
-			"ketch_app":       resourceApp(),
-			"ketch_job":       resourceJob(),
-			"ketch_framework": resourceFramework(),
+			"ketch_app":          resourceApp(),
+			"ketch_app_scale":    resourceAppScale(),
+			"ketch_app_rollback": resourceAppRollback(),
+			"ketch_app_traffic":  resourceAppTraffic(),
+			"ketch_app_cname":    resourceAppCname(),
+			"ketch_job":          resourceJob(),
+			"ketch_framework":    resourceFramework(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"ketch_canary_plan": dataSourceCanaryPlan(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -22,7 +62,13 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	c, err := client.NewClient()
+	var registry *client.Registry
+	if raw := d.Get("registry").([]interface{}); len(raw) > 0 {
+		registry = &client.Registry{}
+		helper.TerraformToStruct(raw, registry)
+	}
+
+	c, err := client.NewClient(registry)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
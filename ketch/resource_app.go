@@ -2,16 +2,30 @@ package ketch
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/brunoa19/ketch-terraform-provider/client"
 	"github.com/brunoa19/ketch-terraform-provider/helper"
 )
 
 var (
+	schemaAppState = &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validation.StringInSlice([]string{client.AppStateRunning, client.AppStateStopped}, false),
+	}
+
 	processesSchema = &schema.Schema{
 		Type:     schema.TypeList,
 		Optional: true,
@@ -28,6 +42,12 @@ var (
 						Type: schema.TypeString,
 					},
 				},
+				"units": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Computed: true,
+				},
+				"state": schemaAppState,
 			},
 		},
 	}
@@ -35,6 +55,7 @@ var (
 	routingSettingsSchema = &schema.Schema{
 		Type:     schema.TypeList,
 		Optional: true,
+		Computed: true,
 		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
@@ -46,6 +67,146 @@ var (
 		},
 	}
 
+	deploymentSchema = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"version": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"image": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"processes": processesSchema,
+				"weight": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					ValidateFunc: validation.IntBetween(0, 100),
+				},
+				"labels": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"ports": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeInt,
+					},
+				},
+			},
+		},
+	}
+
+	canarySchema = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"steps": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntBetween(1, 100),
+				},
+				"step_weight": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntBetween(1, 100),
+				},
+				"step_interval": {
+					Type:             schema.TypeString,
+					Required:         true,
+					ValidateFunc:     validateDuration,
+					DiffSuppressFunc: suppressEquivalentDuration,
+				},
+			},
+		},
+	}
+
+	canaryScheduleSchema = &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"step": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"time": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"primary_weight": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"canary_weight": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"promoted": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+			},
+		},
+	}
+
+	cnameStatusSchema = &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cname": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"scheme": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"certificate_exists": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+				"certificate_ready": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+				"certificate_unknown": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+			},
+		},
+	}
+
+	dockerRegistrySchema = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"secret_name": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"create_secret": {
+					Type:     schema.TypeBool,
+					Optional: true,
+				},
+			},
+		},
+	}
+
 	schemaApp = map[string]*schema.Schema{
 		// Required
 		"name": {
@@ -53,23 +214,61 @@ var (
 			Required: true,
 			ForceNew: true,
 		},
-		"image": {
-			Type:     schema.TypeString,
-			Required: true,
-		},
 		"framework": {
 			Type:     schema.TypeString,
 			Required: true,
 		},
 
 		// Optional
+		"image": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ExactlyOneOf: []string{"image", "deployment"},
+		},
+		"description": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringLenBetween(0, 140),
+		},
+		"app_version": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"labels": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"annotations": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
 		"cnames": {
 			Type:     schema.TypeList,
 			Optional: true,
 			Elem: &schema.Schema{
-				Type: schema.TypeString,
+				Type:         schema.TypeString,
+				ValidateFunc: validateCname,
 			},
 		},
+		"ignore_external_cnames": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"wait_for_certificates": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"generate_default_cname": {
+			Type:     schema.TypeBool,
+			Optional: true,
+			Default:  true,
+		},
 		"ports": {
 			Type:     schema.TypeList,
 			Optional: true,
@@ -80,6 +279,12 @@ var (
 		"units": {
 			Type:     schema.TypeInt,
 			Optional: true,
+			Computed: true,
+		},
+		"deployments_count": {
+			Type:     schema.TypeInt,
+			Optional: true,
+			Computed: true,
 		},
 
 		"processes": processesSchema,
@@ -87,9 +292,88 @@ var (
 		"routing_settings": routingSettingsSchema,
 
 		"version": {
-			Type:     schema.TypeInt,
+			Type:          schema.TypeInt,
+			Optional:      true,
+			Computed:      true,
+			ConflictsWith: []string{"auto_version", "canary"},
+		},
+		"auto_version": {
+			Type:          schema.TypeBool,
+			Optional:      true,
+			ConflictsWith: []string{"deployment"},
+		},
+
+		"state": schemaAppState,
+
+		"deployment_labels": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+
+		"deployment": deploymentSchema,
+
+		"docker_registry": dockerRegistrySchema,
+
+		"builder": {
+			Type:     schema.TypeString,
 			Optional: true,
 		},
+		"buildpacks": {
+			Type:         schema.TypeList,
+			Optional:     true,
+			RequiredWith: []string{"builder"},
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+
+		"canary": canarySchema,
+
+		"wait_for_canary": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"canary_failure_threshold": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateDuration,
+			RequiredWith: []string{"wait_for_canary"},
+		},
+
+		// Computed
+		"default_cname": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"urls": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"total_units": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"phase": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"canary_active": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"canary_current_step": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"canary_schedule": canaryScheduleSchema,
+
+		"cname_status": cnameStatusSchema,
 	}
 )
 
@@ -103,7 +387,95 @@ func resourceApp() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceAppCustomizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+		},
+	}
+}
+
+func resourceAppCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	var app client.App
+	helper.TerraformToStruct(map[string]interface{}{
+		"deployment": d.Get("deployment"),
+		"canary":     d.Get("canary"),
+	}, &app)
+
+	if d.NewValueKnown("deployment") {
+		if err := client.ValidateDeployments(app.Deployments); err != nil {
+			return err
+		}
+	}
+
+	// cnames claimed by other apps would collide at the ingress
+	if d.NewValueKnown("cnames") && (d.Id() == "" || d.HasChange("cnames")) {
+		var cnames []string
+		for _, cname := range d.Get("cnames").([]interface{}) {
+			cnames = append(cnames, cname.(string))
+		}
+		c := m.(*client.Client)
+		if err := c.CheckCnames(ctx, d.Get("name").(string), cnames); err != nil {
+			return err
+		}
+	}
+
+	// a new deployment version is known only when the app is updated
+	if d.Id() != "" && d.Get("auto_version").(bool) && deploymentChanged(d) {
+		if err := d.SetNewComputed("version"); err != nil {
+			return err
+		}
+		if err := d.SetNewComputed("deployments_count"); err != nil {
+			return err
+		}
+	}
+
+	// a canary started by a change of the image is checked, its schedule is known only when the canary starts,
+	// times of steps can be previewed by the ketch_canary_plan data source
+	if d.Id() != "" && app.Canary != nil && len(app.Deployments) == 0 && d.HasChange("image") {
+		if _, err := app.Canary.Schedule(time.Now()); err != nil {
+			return err
+		}
+		return d.SetNewComputed("canary_schedule")
 	}
+	return nil
+}
+
+// validateCname checks that a cname is a hostname as defined by RFC 1123.
+func validateCname(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	var errs []error
+	for _, msg := range k8svalidation.IsDNS1123Subdomain(v) {
+		errs = append(errs, fmt.Errorf("expected %s to be a hostname: %s", k, msg))
+	}
+	return nil, errs
+}
+
+func validateDuration(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	if _, err := time.ParseDuration(v); err != nil {
+		return nil, []error{fmt.Errorf("expected %s to be a duration: %w", k, err)}
+	}
+	return nil, nil
+}
+
+// suppressEquivalentDuration suppresses a diff between durations written differently, e.g. "1m" and "1m0s".
+func suppressEquivalentDuration(k, old, new string, d *schema.ResourceData) bool {
+	oldDuration, err := time.ParseDuration(old)
+	if err != nil {
+		return false
+	}
+	newDuration, err := time.ParseDuration(new)
+	if err != nil {
+		return false
+	}
+	return oldDuration == newDuration
 }
 
 func extractApp(d *schema.ResourceData) *client.App {
@@ -118,6 +490,9 @@ func resourceAppCreate(ctx context.Context, d *schema.ResourceData, m interface{
 	var diags diag.Diagnostics
 
 	app := extractApp(d)
+	if d.Get("auto_version").(bool) {
+		app.Version = 1
+	}
 	log.Printf("CONVERTED app: %+v\n", app)
 
 	c := m.(*client.Client)
@@ -128,6 +503,13 @@ func resourceAppCreate(ctx context.Context, d *schema.ResourceData, m interface{
 
 	d.SetId(app.Name)
 
+	if d.Get("wait_for_certificates").(bool) {
+		err = c.WaitForCertificates(ctx, app.Name, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	resourceAppRead(ctx, d, m)
 
 	return diags
@@ -149,59 +531,414 @@ func resourceAppRead(ctx context.Context, d *schema.ResourceData, m interface{})
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("image", app.Image)
+	err = d.Set("framework", app.Framework)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("framework", app.Framework)
+	err = d.Set("description", app.Description)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("app_version", app.AppVersion)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// labels and annotations added by ketch controller and kubernetes are not managed
+	err = d.Set("labels", managedValues(app.Labels))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("annotations", managedValues(app.Annotations))
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	// cnames added outside of the app, e.g. by ketch_app_cname, can be ignored
+	if d.Get("ignore_external_cnames").(bool) {
+		app.Cname = declaredCnames(app.Cname, d.Get("cnames"))
+	}
 	err = d.Set("cnames", app.Cname)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("ports", app.Ports)
+	err = d.Set("generate_default_cname", app.GenerateDefaultCname)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("default_cname", app.DefaultCname)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("urls", app.URLs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("cname_status", helper.StructToTerraform(&app.CnameStatus))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("units", appUnits(d.Get("units").(int), app))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("state", app.State)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("phase", app.Phase)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("units", app.Units)
+	err = d.Set("deployments_count", app.DeploymentsCount)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("processes", helper.StructToTerraform(&app.Processes))
+	err = d.Set("total_units", app.TotalUnits)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("routing_settings", helper.StructToTerraform(app.RoutingSettings))
+	err = d.Set("builder", app.Builder)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("version", app.Version)
+	err = d.Set("buildpacks", app.BuildPacks)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if app.DockerRegistry != nil {
+		// creation of the secret is not reflected in the app,
+		// a created secret removed outside of terraform is reported as not created to be created again
+		app.DockerRegistry.CreateSecret = d.Get("docker_registry.0.create_secret").(bool)
+		if app.DockerRegistry.CreateSecret {
+			app.DockerRegistry.CreateSecret, err = c.RegistrySecretExists(ctx, app.Framework, app.DockerRegistry.SecretName)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+		err = d.Set("docker_registry", helper.StructToTerraform(app.DockerRegistry))
+	} else {
+		err = d.Set("docker_registry", nil)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if app.Canary != nil {
+		err = d.Set("canary", helper.StructToTerraform(app.Canary))
+	} else {
+		err = d.Set("canary", nil)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("canary_active", app.CanaryActive)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("canary_current_step", app.CanaryCurrentStep)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("canary_schedule", helper.StructToTerraform(&app.CanarySchedule))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// deployments are listed explicitly or defined by the top level attributes
+	if len(d.Get("deployment").([]interface{})) > 0 {
+		// common labels are reported once, not by every deployment
+		err = d.Set("deployment_labels", managedValues(app.DeploymentLabels))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for i, deployment := range app.Deployments {
+			keepStoppedProcessUnits(d.Get(fmt.Sprintf("deployment.%d.processes", i)), deployment.Processes)
+			removeCommonLabels(deployment, d.Get("deployment_labels"), d.Get(fmt.Sprintf("deployment.%d.labels", i)))
+		}
+		err = d.Set("deployment", helper.StructToTerraform(&app.Deployments))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	} else {
+		err = d.Set("image", app.Image)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = d.Set("ports", app.Ports)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = d.Set("deployment_labels", app.DeploymentLabels)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		keepStoppedProcessUnits(d.Get("processes"), app.Processes)
+		err = d.Set("processes", helper.StructToTerraform(&app.Processes))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = d.Set("routing_settings", helper.StructToTerraform(app.RoutingSettings))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		err = d.Set("version", app.Version)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	return diags
 }
 
+// controllerKeyPrefixes are prefixes of labels and annotations set by ketch controller and kubernetes.
+var controllerKeyPrefixes = []string{"theketch.io/", "kubernetes.io/", "kubectl.kubernetes.io/"}
+
+// managedValues returns values managed by terraform, values of keys set by ketch controller and kubernetes are left out.
+func managedValues(values map[string]string) map[string]string {
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		if !hasControllerPrefix(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func hasControllerPrefix(key string) bool {
+	for _, prefix := range controllerKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaredCnames returns cnames known from the state.
+func declaredCnames(cnames []string, raw interface{}) []string {
+	declared, _ := raw.([]interface{})
+	var result []string
+	for _, cname := range cnames {
+		if containsValue(declared, cname) {
+			result = append(result, cname)
+		}
+	}
+	return result
+}
+
+// removeCommonLabels removes common deployment labels from labels of a deployment unless the deployment declares them.
+func removeCommonLabels(deployment *client.Deployment, common interface{}, declared interface{}) {
+	commonLabels, _ := common.(map[string]interface{})
+	declaredLabels, _ := declared.(map[string]interface{})
+	for name, value := range commonLabels {
+		if _, ok := declaredLabels[name]; ok {
+			continue
+		}
+		if deployment.Labels[name] == value {
+			delete(deployment.Labels, name)
+		}
+	}
+}
+
+// removedKeys returns keys of a map attribute removed from the configuration.
+func removedKeys(d *schema.ResourceData, key string) []string {
+	o, n := d.GetChange(key)
+	oldValues, _ := o.(map[string]interface{})
+	newValues, _ := n.(map[string]interface{})
+	var removed []string
+	for k := range oldValues {
+		if _, ok := newValues[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// removedCnames returns cnames removed from the configuration.
+// No cnames are removed when external cnames start being ignored, e.g. after an import,
+// as cnames known from the state may be added outside of the app.
+func removedCnames(d *schema.ResourceData) []string {
+	if d.HasChange("ignore_external_cnames") {
+		return nil
+	}
+	o, n := d.GetChange("cnames")
+	newCnames, _ := n.([]interface{})
+	var removed []string
+	for _, cname := range o.([]interface{}) {
+		if !containsValue(newCnames, cname) {
+			removed = append(removed, cname.(string))
+		}
+	}
+	return removed
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// appUnits returns units of the app to be kept in the state.
+// Units of a stopped app are kept as configured to start the app with the same units,
+// units of an app which processes run different units are kept as configured as well,
+// so units of the app and of its processes can be set together.
+func appUnits(current int, app *client.App) int64 {
+	if app.State == client.AppStateStopped || app.Units == 0 {
+		return int64(current)
+	}
+	return app.Units
+}
+
+// keepStoppedProcessUnits replaces zero units of stopped processes with units known from the state.
+func keepStoppedProcessUnits(raw interface{}, processes []*client.ProcessParameters) {
+	items, _ := raw.([]interface{})
+	for _, item := range items {
+		current, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, p := range processes {
+			if p.State == client.AppStateStopped && p.Name == current["name"] {
+				p.Units = int64(current["units"].(int))
+			}
+		}
+	}
+}
+
+// clearUnchangedUnits resets units and states of the app and its processes which are not changed in the configuration.
+// Units are applied only when changed in the configuration or when the app is started,
+// otherwise units scaled outside of terraform are kept as is.
+func clearUnchangedUnits(d *schema.ResourceData, app *client.App) {
+	started := d.HasChange("state") && app.State == client.AppStateRunning
+	if !d.HasChange("units") && !started {
+		app.Units = 0
+	}
+	if !d.HasChange("state") {
+		app.State = ""
+	}
+	clearUnchangedProcesses(d, "processes", app.Processes, app.Units > 0)
+	for i, deployment := range app.Deployments {
+		clearUnchangedProcesses(d, fmt.Sprintf("deployment.%d.processes", i), deployment.Processes, app.Units > 0)
+	}
+}
+
+// clearUnchangedProcesses resets units and states of processes which are not changed in the configuration.
+// Configured units of processes are kept when units of the app are applied, so they are not overwritten by the units of the app,
+// and when processes are started, so they are started with the configured units.
+func clearUnchangedProcesses(d *schema.ResourceData, key string, processes []*client.ProcessParameters, applyUnits bool) {
+	for i, p := range processes {
+		started := d.HasChange(fmt.Sprintf("%s.%d.state", key, i)) && p.State == client.AppStateRunning
+		if !applyUnits && !started && !d.HasChange(fmt.Sprintf("%s.%d.units", key, i)) {
+			p.Units = 0
+		}
+		if !d.HasChange(fmt.Sprintf("%s.%d.state", key, i)) {
+			p.State = ""
+		}
+	}
+}
+
+// resourceChange is implemented by schema.ResourceData and schema.ResourceDiff.
+type resourceChange interface {
+	HasChange(key string) bool
+	GetChange(key string) (interface{}, interface{})
+}
+
+// deploymentChanged checks if the latest deployment is changed, so a new deployment version is used with auto_version.
+func deploymentChanged(d resourceChange) bool {
+	return d.HasChange("image") || d.HasChange("ports") || processCommandsChanged(d)
+}
+
+// processCommandsChanged checks if processes are added, removed or run different commands,
+// changes of units and states of processes don't change a deployment.
+func processCommandsChanged(d resourceChange) bool {
+	o, n := d.GetChange("processes")
+	oldProcesses, newProcesses := o.([]interface{}), n.([]interface{})
+	if len(oldProcesses) != len(newProcesses) {
+		return true
+	}
+	for i := range oldProcesses {
+		oldProcess, _ := oldProcesses[i].(map[string]interface{})
+		newProcess, _ := newProcesses[i].(map[string]interface{})
+		if oldProcess["name"] != newProcess["name"] || !reflect.DeepEqual(oldProcess["cmd"], newProcess["cmd"]) {
+			return true
+		}
+	}
+	return false
+}
+
 func resourceAppUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	if !d.HasChange("") {
 		return resourceAppRead(ctx, d, m)
 	}
 
 	app := extractApp(d)
+	clearUnchangedUnits(d, app)
+	app.RemovedLabels = removedKeys(d, "labels")
+	app.RemovedAnnotations = removedKeys(d, "annotations")
+	app.RemovedCnames = removedCnames(d)
+
+	c := m.(*client.Client)
+	// a new deployment version is used when the deployment changes
+	if d.Get("auto_version").(bool) && deploymentChanged(d) {
+		version, err := c.NextDeploymentVersion(ctx, app.Name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		app.Version = version
+		app.DeploymentsCount = version
+	}
 
 	log.Printf(" ### CONVERTED app data: %+v\n", *app)
 
-	c := m.(*client.Client)
-	err := c.UpdateApp(ctx, app)
+	var err error
+	if app.Canary != nil && len(app.Deployments) == 0 && d.HasChange("image") {
+		err = c.StartCanary(ctx, app)
+	} else {
+		err = c.UpdateApp(ctx, app)
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
+	if d.HasChange("docker_registry") || d.HasChange("framework") {
+		err = deleteReplacedRegistrySecret(ctx, d, c)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.Get("wait_for_canary").(bool) {
+		var failureThreshold time.Duration
+		if v, ok := d.GetOk("canary_failure_threshold"); ok {
+			failureThreshold, _ = time.ParseDuration(v.(string))
+		}
+		err = c.WaitForCanary(ctx, app.Name, d.Timeout(schema.TimeoutUpdate), failureThreshold)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return resourceAppRead(ctx, d, m)
 }
 
+// deleteReplacedRegistrySecret deletes a registry secret created for the app before
+// if the secret is renamed, is not created anymore or the app is moved to another framework.
+func deleteReplacedRegistrySecret(ctx context.Context, d *schema.ResourceData, c *client.Client) error {
+	o, n := d.GetChange("docker_registry")
+	var oldApp, newApp client.App
+	helper.TerraformToStruct(map[string]interface{}{"docker_registry": o}, &oldApp)
+	helper.TerraformToStruct(map[string]interface{}{"docker_registry": n}, &newApp)
+
+	if oldApp.DockerRegistry == nil || !oldApp.DockerRegistry.CreateSecret {
+		return nil
+	}
+	oldFramework, newFramework := d.GetChange("framework")
+	if oldFramework == newFramework && newApp.DockerRegistry != nil && newApp.DockerRegistry.CreateSecret &&
+		newApp.DockerRegistry.SecretName == oldApp.DockerRegistry.SecretName {
+		return nil
+	}
+	return c.DeleteRegistrySecret(ctx, oldFramework.(string), oldApp.DockerRegistry.SecretName)
+}
+
 func resourceAppDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
@@ -213,6 +950,13 @@ func resourceAppDelete(ctx context.Context, d *schema.ResourceData, m interface{
 		return diag.FromErr(err)
 	}
 
+	if d.Get("docker_registry.0.create_secret").(bool) {
+		err = c.DeleteRegistrySecret(ctx, d.Get("framework").(string), d.Get("docker_registry.0.secret_name").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	// d.SetId("") is automatically called assuming delete returns no errors, but
 	// it is added here for explicitness.
 	d.SetId("")
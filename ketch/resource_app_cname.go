@@ -0,0 +1,152 @@
+package ketch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+	"github.com/brunoa19/ketch-terraform-provider/helper"
+)
+
+func resourceAppCname() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAppCnameCreate,
+		ReadContext:   resourceAppCnameRead,
+		DeleteContext: resourceAppCnameDelete,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"cname": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateCname,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAppCnameImport,
+		},
+		CustomizeDiff: resourceAppCnameCustomizeDiff,
+	}
+}
+
+func resourceAppCnameCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" || !d.NewValueKnown("app") || !d.NewValueKnown("cname") {
+		return nil
+	}
+	c := m.(*client.Client)
+	return c.CheckAppCname(ctx, &client.AppCname{
+		App:   d.Get("app").(string),
+		Cname: d.Get("cname").(string),
+	})
+}
+
+// appCnameID returns an ID in the "<app>:<cname>" format.
+func appCnameID(cname *client.AppCname) string {
+	return fmt.Sprintf("%s:%s", cname.App, cname.Cname)
+}
+
+func parseAppCnameID(id string) (*client.AppCname, error) {
+	parts := strings.Split(id, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid app cname id %q, expected <app>:<cname>", id)
+	}
+	return &client.AppCname{
+		App:   parts[0],
+		Cname: parts[1],
+	}, nil
+}
+
+func extractAppCname(d *schema.ResourceData) *client.AppCname {
+	raw := d.Get("")
+	var cname client.AppCname
+	helper.TerraformToStruct(raw, &cname)
+	return &cname
+}
+
+func resourceAppCnameImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	cname, err := parseAppCnameID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range map[string]interface{}{
+		"app":   cname.App,
+		"cname": cname.Cname,
+	} {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceAppCnameCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	cname := extractAppCname(d)
+	log.Printf("CONVERTED app cname: %+v\n", cname)
+
+	c := m.(*client.Client)
+	err := c.AddAppCname(ctx, cname)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(appCnameID(cname))
+
+	resourceAppCnameRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceAppCnameRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	input, err := parseAppCnameID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	c := m.(*client.Client)
+	_, err = c.GetAppCname(ctx, input)
+	if errors.Is(err, client.ErrCnameNotFound) {
+		// the cname is removed from the app outside of terraform
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceAppCnameDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	cname := extractAppCname(d)
+
+	c := m.(*client.Client)
+	err := c.RemoveAppCname(ctx, cname)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	return diags
+}
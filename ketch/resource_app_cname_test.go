@@ -0,0 +1,36 @@
+package ketch
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+)
+
+func TestExtractAppCname(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAppCname().Schema, map[string]interface{}{
+		"app":   "testapp",
+		"cname": "vanity.example.com",
+	})
+	expected := &client.AppCname{
+		App:   "testapp",
+		Cname: "vanity.example.com",
+	}
+
+	cname := extractAppCname(d)
+	require.Equal(t, expected, cname)
+	require.Equal(t, "testapp:vanity.example.com", appCnameID(cname))
+}
+
+func TestParseAppCnameID(t *testing.T) {
+	cname, err := parseAppCnameID("testapp:vanity.example.com")
+	require.NoError(t, err)
+	require.Equal(t, &client.AppCname{App: "testapp", Cname: "vanity.example.com"}, cname)
+
+	_, err = parseAppCnameID("testapp")
+	require.Error(t, err)
+	_, err = parseAppCnameID("testapp:")
+	require.Error(t, err)
+}
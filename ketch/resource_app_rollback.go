@@ -0,0 +1,95 @@
+package ketch
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+	"github.com/brunoa19/ketch-terraform-provider/helper"
+)
+
+func resourceAppRollback() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAppRollbackCreate,
+		ReadContext:   resourceAppRollbackRead,
+		DeleteContext: resourceAppRollbackDelete,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"trigger": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"reason": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"rolled_back_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"removed_version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func extractAppRollback(d *schema.ResourceData) *client.AppRollback {
+	raw := d.Get("")
+	var rollback client.AppRollback
+	helper.TerraformToStruct(raw, &rollback)
+	return &rollback
+}
+
+func resourceAppRollbackCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	rollback := extractAppRollback(d)
+	log.Printf("CONVERTED app rollback: %+v\n", rollback)
+
+	c := m.(*client.Client)
+	err := c.RollbackApp(ctx, rollback)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", rollback.App, rollback.Trigger))
+
+	err = d.Set("rolled_back_at", rollback.RolledBackAt)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("removed_version", rollback.RemovedVersion)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceAppRollbackRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// a rollback is an action, there is nothing to read back from the cluster
+	return nil
+}
+
+func resourceAppRollbackDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	// a rollback can't be undone, the resource is only removed from the state.
+	d.SetId("")
+
+	return diags
+}
@@ -0,0 +1,26 @@
+package ketch
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+)
+
+func TestExtractAppRollback(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAppRollback().Schema, map[string]interface{}{
+		"app":     "testapp",
+		"trigger": "incident-42",
+		"reason":  "error rate is too high",
+	})
+	expected := &client.AppRollback{
+		App:     "testapp",
+		Trigger: "incident-42",
+		Reason:  "error rate is too high",
+	}
+
+	rollback := extractAppRollback(d)
+	require.Equal(t, expected, rollback)
+}
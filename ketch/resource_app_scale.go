@@ -0,0 +1,178 @@
+package ketch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+	"github.com/brunoa19/ketch-terraform-provider/helper"
+)
+
+func resourceAppScale() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAppScaleCreate,
+		ReadContext:   resourceAppScaleRead,
+		UpdateContext: resourceAppScaleUpdate,
+		DeleteContext: resourceAppScaleDelete,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"process": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"deployment_version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				ForceNew: true,
+			},
+			"units": {
+				Type:         schema.TypeInt,
+				Required:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAppScaleImport,
+		},
+	}
+}
+
+// appScaleID returns an ID in the "<app>:<process>:<deployment version>" format.
+func appScaleID(scale *client.AppScale) string {
+	return fmt.Sprintf("%s:%s:%d", scale.App, scale.Process, scale.DeploymentVersion)
+}
+
+func parseAppScaleID(id string) (*client.AppScale, error) {
+	parts := strings.Split(id, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid app scale id %q, expected <app>:<process>:<deployment version>", id)
+	}
+	var version int64
+	if parts[2] != "" {
+		var err error
+		version, err = strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment version in app scale id %q: %w", id, err)
+		}
+	}
+	return &client.AppScale{
+		App:               parts[0],
+		Process:           parts[1],
+		DeploymentVersion: version,
+	}, nil
+}
+
+func extractAppScale(d *schema.ResourceData) *client.AppScale {
+	raw := d.Get("")
+	var scale client.AppScale
+	helper.TerraformToStruct(raw, &scale)
+	return &scale
+}
+
+func resourceAppScaleImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	scale, err := parseAppScaleID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range map[string]interface{}{
+		"app":                scale.App,
+		"process":            scale.Process,
+		"deployment_version": scale.DeploymentVersion,
+	} {
+		if err := d.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceAppScaleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	scale := extractAppScale(d)
+	log.Printf("CONVERTED app scale: %+v\n", scale)
+
+	c := m.(*client.Client)
+	err := c.ScaleApp(ctx, scale)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(appScaleID(scale))
+
+	resourceAppScaleRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceAppScaleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	input, err := parseAppScaleID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	c := m.(*client.Client)
+	scale, err := c.GetAppScale(ctx, input)
+	if errors.Is(err, client.ErrDeploymentNotFound) || errors.Is(err, client.ErrProcessNotFound) {
+		// the deployment or the process is removed from the app, e.g. by a promoted canary
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// units are read back as -1 when the selected processes run different units,
+	// so the drift is planned and the processes are scaled to the same units again
+	err = d.Set("units", scale.Units)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceAppScaleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if !d.HasChange("") {
+		return resourceAppScaleRead(ctx, d, m)
+	}
+
+	scale := extractAppScale(d)
+
+	c := m.(*client.Client)
+	err := c.ScaleApp(ctx, scale)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceAppScaleRead(ctx, d, m)
+}
+
+func resourceAppScaleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	// units are left as they are, the resource only stops managing them.
+	d.SetId("")
+
+	return diags
+}
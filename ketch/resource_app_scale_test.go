@@ -0,0 +1,38 @@
+package ketch
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+)
+
+func TestExtractAppScale(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAppScale().Schema, map[string]interface{}{
+		"app":                "testapp",
+		"process":            "worker",
+		"deployment_version": 2,
+		"units":              3,
+	})
+	expected := &client.AppScale{
+		App:               "testapp",
+		Process:           "worker",
+		DeploymentVersion: 2,
+		Units:             3,
+	}
+
+	scale := extractAppScale(d)
+	require.Equal(t, expected, scale)
+	require.Equal(t, "testapp:worker:2", appScaleID(scale))
+}
+
+func TestParseAppScaleID(t *testing.T) {
+	scale, err := parseAppScaleID("testapp::0")
+	require.NoError(t, err)
+	require.Equal(t, &client.AppScale{App: "testapp"}, scale)
+
+	_, err = parseAppScaleID("testapp")
+	require.Error(t, err)
+}
@@ -1,9 +1,11 @@
 package ketch
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 
 	"github.com/brunoa19/ketch-terraform-provider/client"
@@ -11,20 +13,26 @@ import (
 
 func TestExtractApp(t *testing.T) {
 	d := schema.TestResourceDataRaw(t, schemaApp, map[string]interface{}{
-		"name":      "testjob",
-		"image":     "gcr.io/test",
-		"framework": "testfw",
-		"cnames":    []interface{}{"cname1", "cname2"},
-		"ports":     []interface{}{8080, 8081},
-		"units":     4,
+		"name":              "testjob",
+		"image":             "gcr.io/test",
+		"framework":         "testfw",
+		"description":       "test application",
+		"app_version":       "1.2.0",
+		"cnames":            []interface{}{"cname1", "cname2"},
+		"ports":             []interface{}{8080, 8081},
+		"units":             4,
+		"deployments_count": 3,
+		"state":             "running",
 		"processes": []interface{}{
 			map[string]interface{}{
 				"cmd":  []interface{}{"./web"},
 				"name": "web",
 			},
 			map[string]interface{}{
-				"cmd":  []interface{}{"./worker", "-v"},
-				"name": "worker",
+				"cmd":   []interface{}{"./worker", "-v"},
+				"name":  "worker",
+				"units": 2,
+				"state": "stopped",
 			},
 		},
 		"routing_settings": []interface{}{
@@ -45,25 +53,344 @@ func TestExtractApp(t *testing.T) {
 		},
 	})
 	expected := &client.App{
-		Name:      "testjob",
-		Image:     "gcr.io/test",
-		Framework: "testfw",
-		Cname:     []string{"cname1", "cname2"},
-		Ports:     []int{8080, 8081},
-		Units:     4,
+		Name:                 "testjob",
+		Image:                "gcr.io/test",
+		Framework:            "testfw",
+		Labels:               map[string]string{},
+		Annotations:          map[string]string{},
+		Description:          "test application",
+		AppVersion:           "1.2.0",
+		Cname:                []string{"cname1", "cname2"},
+		GenerateDefaultCname: true,
+		Ports:                []int{8080, 8081},
+		Units:                4,
+		DeploymentsCount:     3,
+		State:                "running",
 		Processes: []*client.ProcessParameters{
 			{
 				Cmd:  []string{"./web"},
 				Name: "web",
 			},
 			{
-				Cmd:  []string{"./worker", "-v"},
-				Name: "worker",
+				Cmd:   []string{"./worker", "-v"},
+				Name:  "worker",
+				Units: 2,
+				State: "stopped",
 			},
 		},
-		RoutingSettings: &client.RoutingSettings{Weight: 100},
-		Version:         2,
+		RoutingSettings:  &client.RoutingSettings{Weight: 100},
+		DeploymentLabels: map[string]string{},
+		Version:          2,
 	}
 	app := extractApp(d)
 	require.Equal(t, expected, app)
 }
+
+func TestAppUnits(t *testing.T) {
+	running := &client.App{
+		State: client.AppStateRunning,
+		Processes: []*client.ProcessParameters{
+			{Name: "web", Units: 4},
+			{Name: "worker", Units: 2},
+		},
+	}
+	// units of the app are kept when processes run different units
+	require.Equal(t, int64(4), appUnits(4, running))
+
+	running.Units = 3
+	require.Equal(t, int64(3), appUnits(4, running))
+
+	stopped := &client.App{State: client.AppStateStopped}
+	require.Equal(t, int64(3), appUnits(3, stopped))
+}
+
+func TestClearUnchangedUnits(t *testing.T) {
+	stopped := map[string]interface{}{
+		"name":      "testapp",
+		"image":     "gcr.io/test",
+		"framework": "testfw",
+		"units":     3,
+		"state":     "stopped",
+		"processes": []interface{}{
+			map[string]interface{}{"name": "web", "cmd": []interface{}{"./web"}},
+			map[string]interface{}{"name": "worker", "cmd": []interface{}{"./worker"}, "units": 2, "state": "stopped"},
+		},
+	}
+	started := map[string]interface{}{
+		"name":      "testapp",
+		"image":     "gcr.io/test",
+		"framework": "testfw",
+		"units":     3,
+		"state":     "running",
+		"processes": []interface{}{
+			map[string]interface{}{"name": "web", "cmd": []interface{}{"./web"}},
+			map[string]interface{}{"name": "worker", "cmd": []interface{}{"./worker"}, "units": 2, "state": "running"},
+		},
+	}
+
+	// a started app is run with the configured units
+	d := testResourceDataUpdate(t, schemaApp, stopped, started)
+	app := extractApp(d)
+	clearUnchangedUnits(d, app)
+	require.Equal(t, int64(3), app.Units)
+	require.Equal(t, client.AppStateRunning, app.State)
+	require.Equal(t, int64(2), app.Processes[1].Units)
+
+	// units of a running app are kept as scaled outside of terraform
+	d = testResourceDataUpdate(t, schemaApp, started, started)
+	app = extractApp(d)
+	clearUnchangedUnits(d, app)
+	require.Equal(t, int64(0), app.Units)
+	require.Empty(t, app.State)
+	require.Equal(t, int64(0), app.Processes[1].Units)
+}
+
+func TestAutoVersionDiff(t *testing.T) {
+	current := map[string]interface{}{
+		"name":         "testapp",
+		"image":        "gcr.io/test:1",
+		"framework":    "testfw",
+		"auto_version": true,
+		"processes": []interface{}{
+			map[string]interface{}{"name": "web", "cmd": []interface{}{"./web"}},
+		},
+	}
+	planned := func(changes map[string]interface{}) *terraform.InstanceDiff {
+		config := make(map[string]interface{}, len(current))
+		for k, v := range current {
+			config[k] = v
+		}
+		for k, v := range changes {
+			config[k] = v
+		}
+		state := schema.TestResourceDataRaw(t, schemaApp, current)
+		state.SetId("testapp")
+		resource := &schema.Resource{Schema: schemaApp, CustomizeDiff: resourceAppCustomizeDiff}
+		diff, err := resource.Diff(context.Background(), state.State(), terraform.NewResourceConfigRaw(config), nil)
+		require.NoError(t, err)
+		return diff
+	}
+
+	// a new image is deployed with a new version
+	diff := planned(map[string]interface{}{"image": "gcr.io/test:2"})
+	require.True(t, diff.Attributes["version"].NewComputed)
+	require.True(t, diff.Attributes["deployments_count"].NewComputed)
+
+	diff = planned(map[string]interface{}{
+		"processes": []interface{}{
+			map[string]interface{}{"name": "web", "cmd": []interface{}{"./web", "-v"}},
+		},
+	})
+	require.True(t, diff.Attributes["version"].NewComputed)
+
+	// scaling doesn't change the deployment
+	diff = planned(map[string]interface{}{
+		"processes": []interface{}{
+			map[string]interface{}{"name": "web", "cmd": []interface{}{"./web"}, "units": 2},
+		},
+	})
+	require.Nil(t, diff.Attributes["version"])
+}
+
+func TestExtractAppDeployments(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaApp, map[string]interface{}{
+		"name":      "testapp",
+		"framework": "testfw",
+		"deployment": []interface{}{
+			map[string]interface{}{
+				"version": 1,
+				"image":   "gcr.io/test:1",
+				"weight":  80,
+				"ports":   []interface{}{8080},
+			},
+			map[string]interface{}{
+				"version": 2,
+				"image":   "gcr.io/test:2",
+				"weight":  20,
+				"labels":  map[string]interface{}{"track": "canary"},
+				"processes": []interface{}{
+					map[string]interface{}{
+						"cmd":  []interface{}{"./web"},
+						"name": "web",
+					},
+				},
+			},
+		},
+	})
+	expected := &client.App{
+		Name:                 "testapp",
+		Framework:            "testfw",
+		Labels:               map[string]string{},
+		Annotations:          map[string]string{},
+		GenerateDefaultCname: true,
+		DeploymentLabels:     map[string]string{},
+		Deployments: []*client.Deployment{
+			{
+				Version: 1,
+				Image:   "gcr.io/test:1",
+				Weight:  80,
+				Labels:  map[string]string{},
+				Ports:   []int{8080},
+			},
+			{
+				Version: 2,
+				Image:   "gcr.io/test:2",
+				Weight:  20,
+				Labels:  map[string]string{"track": "canary"},
+				Processes: []*client.ProcessParameters{
+					{
+						Cmd:  []string{"./web"},
+						Name: "web",
+					},
+				},
+			},
+		},
+	}
+	app := extractApp(d)
+	require.Equal(t, expected, app)
+	require.NoError(t, client.ValidateDeployments(app.Deployments))
+}
+
+func TestExtractAppCanary(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaApp, map[string]interface{}{
+		"name":      "testapp",
+		"image":     "gcr.io/test",
+		"framework": "testfw",
+		"canary": []interface{}{
+			map[string]interface{}{
+				"steps":         4,
+				"step_weight":   25,
+				"step_interval": "5m",
+			},
+		},
+	})
+	expected := &client.Canary{
+		Steps:        4,
+		StepWeight:   25,
+		StepInterval: "5m",
+	}
+	app := extractApp(d)
+	require.Equal(t, expected, app.Canary)
+}
+
+func TestSuppressEquivalentDuration(t *testing.T) {
+	require.True(t, suppressEquivalentDuration("", "5m0s", "5m", nil))
+	require.False(t, suppressEquivalentDuration("", "5m0s", "10m", nil))
+	require.False(t, suppressEquivalentDuration("", "", "10m", nil))
+}
+
+func TestExtractAppDockerRegistry(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaApp, map[string]interface{}{
+		"name":      "testapp",
+		"image":     "registry.example.com/test",
+		"framework": "testfw",
+		"docker_registry": []interface{}{
+			map[string]interface{}{
+				"secret_name":   "registry",
+				"create_secret": true,
+			},
+		},
+	})
+	expected := &client.DockerRegistry{
+		SecretName:   "registry",
+		CreateSecret: true,
+	}
+	app := extractApp(d)
+	require.Equal(t, expected, app.DockerRegistry)
+}
+
+func TestExtractAppLabels(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaApp, map[string]interface{}{
+		"name":              "testapp",
+		"image":             "gcr.io/test",
+		"framework":         "testfw",
+		"labels":            map[string]interface{}{"team": "payments"},
+		"annotations":       map[string]interface{}{"owner": "alice"},
+		"deployment_labels": map[string]interface{}{"mesh": "enabled"},
+	})
+	app := extractApp(d)
+	require.Equal(t, map[string]string{"team": "payments"}, app.Labels)
+	require.Equal(t, map[string]string{"owner": "alice"}, app.Annotations)
+	require.Equal(t, map[string]string{"mesh": "enabled"}, app.DeploymentLabels)
+}
+
+func TestManagedValues(t *testing.T) {
+	values := map[string]string{
+		"team":                        "payments",
+		"app.kubernetes.io/part-of":   "shop",
+		"theketch.io/framework":       "testfw",
+		"kubernetes.io/metadata.name": "ketch-testfw",
+	}
+	// values are reported without state, e.g. on import
+	managed := managedValues(values)
+	require.Equal(t, map[string]string{"team": "payments", "app.kubernetes.io/part-of": "shop"}, managed)
+}
+
+func TestRemoveCommonLabels(t *testing.T) {
+	deployment := &client.Deployment{
+		Labels: map[string]string{"mesh": "enabled", "tier": "backend", "track": "stable"},
+	}
+	removeCommonLabels(deployment,
+		map[string]interface{}{"mesh": "enabled", "tier": "backend", "track": "canary"},
+		map[string]interface{}{"tier": "backend"},
+	)
+	require.Equal(t, map[string]string{"tier": "backend", "track": "stable"}, deployment.Labels)
+}
+
+func TestDeclaredCnames(t *testing.T) {
+	cnames := declaredCnames(
+		[]string{"testapp.example.com", "vanity.example.com"},
+		[]interface{}{"testapp.example.com", "new.example.com"},
+	)
+	require.Equal(t, []string{"testapp.example.com"}, cnames)
+}
+
+func TestRemovedCnames(t *testing.T) {
+	imported := map[string]interface{}{
+		"name":      "testapp",
+		"image":     "gcr.io/test",
+		"framework": "testfw",
+		"cnames":    []interface{}{"testapp.example.com", "vanity.example.com"},
+	}
+	declared := map[string]interface{}{
+		"name":                   "testapp",
+		"image":                  "gcr.io/test",
+		"framework":              "testfw",
+		"cnames":                 []interface{}{"testapp.example.com"},
+		"ignore_external_cnames": true,
+	}
+	// cnames of an imported app may be added outside of the app
+	d := testResourceDataUpdate(t, schemaApp, imported, declared)
+	require.Empty(t, removedCnames(d))
+
+	d = testResourceDataUpdate(t, schemaApp, declared, map[string]interface{}{
+		"name":                   "testapp",
+		"image":                  "gcr.io/test",
+		"framework":              "testfw",
+		"ignore_external_cnames": true,
+	})
+	require.Equal(t, []string{"testapp.example.com"}, removedCnames(d))
+}
+
+func TestValidateCname(t *testing.T) {
+	_, errs := validateCname("testapp.example.com", "cnames.0")
+	require.Empty(t, errs)
+
+	for _, cname := range []string{"", "Testapp.example.com", "testapp_example.com", "https://testapp.example.com", "-testapp.example.com"} {
+		_, errs = validateCname(cname, "cnames.0")
+		require.NotEmpty(t, errs, cname)
+	}
+}
+
+// testResourceDataUpdate returns resource data of an update from the old configuration to the new one.
+func testResourceDataUpdate(t *testing.T, s map[string]*schema.Schema, old, new map[string]interface{}) *schema.ResourceData {
+	current := schema.TestResourceDataRaw(t, s, old)
+	current.SetId("test")
+	state := current.State()
+
+	diff, err := (&schema.Resource{Schema: s}).Diff(context.Background(), state, terraform.NewResourceConfigRaw(new), nil)
+	require.NoError(t, err)
+	d, err := schema.InternalMap(s).Data(state, diff)
+	require.NoError(t, err)
+	return d
+}
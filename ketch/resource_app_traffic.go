@@ -0,0 +1,171 @@
+package ketch
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+	"github.com/brunoa19/ketch-terraform-provider/helper"
+)
+
+func resourceAppTraffic() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAppTrafficCreate,
+		ReadContext:   resourceAppTrafficRead,
+		UpdateContext: resourceAppTrafficUpdate,
+		DeleteContext: resourceAppTrafficDelete,
+		Schema: map[string]*schema.Schema{
+			"app": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"deployment": {
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"version": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"weight": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(0, 100),
+						},
+					},
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: resourceAppTrafficCustomizeDiff,
+	}
+}
+
+func resourceAppTrafficCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("deployment") {
+		return nil
+	}
+
+	var traffic client.AppTraffic
+	helper.TerraformToStruct(map[string]interface{}{"deployment": d.Get("deployment")}, &traffic)
+	return traffic.Validate()
+}
+
+func extractAppTraffic(d *schema.ResourceData) *client.AppTraffic {
+	raw := d.Get("")
+	var traffic client.AppTraffic
+	helper.TerraformToStruct(raw, &traffic)
+	return &traffic
+}
+
+func resourceAppTrafficCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	traffic := extractAppTraffic(d)
+	log.Printf("CONVERTED app traffic: %+v\n", traffic)
+
+	c := m.(*client.Client)
+	err := c.SetAppTraffic(ctx, traffic)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(traffic.App)
+
+	resourceAppTrafficRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceAppTrafficRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	name := d.Id()
+
+	c := m.(*client.Client)
+	traffic, err := c.GetAppTraffic(ctx, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	err = d.Set("app", traffic.App)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	deployments := declaredWeights(traffic.Deployments, d.Get("deployment"))
+	err = d.Set("deployment", helper.StructToTerraform(&deployments))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// declaredWeights returns weights of deployments in the order known from the state.
+// Deployments which are not listed get no traffic, so they are reported only when they get traffic anyway.
+// All deployments are reported when no deployments are known, e.g. on import.
+func declaredWeights(weights []*client.DeploymentWeight, raw interface{}) []*client.DeploymentWeight {
+	declared, _ := raw.([]interface{})
+	if len(declared) == 0 {
+		return weights
+	}
+
+	result := make([]*client.DeploymentWeight, 0, len(weights))
+	listed := make(map[int64]bool, len(declared))
+	for _, item := range declared {
+		deployment, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		version := int64(deployment["version"].(int))
+		listed[version] = true
+		for _, w := range weights {
+			if w.Version == version {
+				result = append(result, w)
+			}
+		}
+	}
+	for _, w := range weights {
+		if !listed[w.Version] && w.Weight > 0 {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+func resourceAppTrafficUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if !d.HasChange("") {
+		return resourceAppTrafficRead(ctx, d, m)
+	}
+
+	traffic := extractAppTraffic(d)
+
+	c := m.(*client.Client)
+	err := c.SetAppTraffic(ctx, traffic)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceAppTrafficRead(ctx, d, m)
+}
+
+func resourceAppTrafficDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	// weights are left as they are, the resource only stops managing them.
+	d.SetId("")
+
+	return diags
+}
@@ -0,0 +1,58 @@
+package ketch
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brunoa19/ketch-terraform-provider/client"
+)
+
+func TestExtractAppTraffic(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAppTraffic().Schema, map[string]interface{}{
+		"app": "testapp",
+		"deployment": []interface{}{
+			map[string]interface{}{"version": 1, "weight": 90},
+			map[string]interface{}{"version": 2, "weight": 10},
+		},
+	})
+	expected := &client.AppTraffic{
+		App: "testapp",
+		Deployments: []*client.DeploymentWeight{
+			{Version: 1, Weight: 90},
+			{Version: 2, Weight: 10},
+		},
+	}
+
+	traffic := extractAppTraffic(d)
+	require.Equal(t, expected, traffic)
+	require.NoError(t, traffic.Validate())
+}
+
+func TestDeclaredWeights(t *testing.T) {
+	weights := []*client.DeploymentWeight{
+		{Version: 1, Weight: 0},
+		{Version: 2, Weight: 90},
+		{Version: 3, Weight: 10},
+	}
+	declared := []interface{}{
+		map[string]interface{}{"version": 3, "weight": 10},
+		map[string]interface{}{"version": 2, "weight": 90},
+	}
+	require.Equal(t, []*client.DeploymentWeight{
+		{Version: 3, Weight: 10},
+		{Version: 2, Weight: 90},
+	}, declaredWeights(weights, declared))
+
+	// a deployment which is not listed but gets traffic is a drift
+	weights[0].Weight = 5
+	require.Equal(t, []*client.DeploymentWeight{
+		{Version: 3, Weight: 10},
+		{Version: 2, Weight: 90},
+		{Version: 1, Weight: 5},
+	}, declaredWeights(weights, declared))
+
+	// all deployments are imported
+	require.Equal(t, weights, declaredWeights(weights, []interface{}{}))
+}
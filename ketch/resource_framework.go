@@ -2,10 +2,13 @@ package ketch
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	"github.com/brunoa19/ketch-terraform-provider/client"
 	"github.com/brunoa19/ketch-terraform-provider/helper"
@@ -52,25 +55,161 @@ func resourceFramework() *schema.Resource {
 				ForceNew: true,
 			},
 			"namespace": {
-				Type:     schema.TypeString,
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressDefaultNamespace,
+			},
+			"namespace_labels": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"namespace_annotations": {
+				Type:     schema.TypeMap,
 				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 			"app_quota_limit": {
-				Type:     schema.TypeInt,
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ValidateFunc:  validation.IntAtLeast(0),
+				ConflictsWith: []string{"unlimited_apps"},
+			},
+			"unlimited_apps": {
+				Type:     schema.TypeBool,
 				Optional: true,
 			},
 			"ingress_controller": schemaIngressController,
+			"wait_for_ready": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"deletion_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      client.FrameworkDeletionBlock,
+				ValidateFunc: validation.StringInSlice(client.FrameworkDeletionPolicies, false),
+			},
+
+			// Computed
+			"phase": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"message": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"namespace_uid": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"namespace_adopted": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"apps": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"jobs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"apps_remaining": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(10 * time.Minute),
+		},
+		CustomizeDiff: resourceFrameworkCustomizeDiff,
+	}
+}
+
+func resourceFrameworkCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	c := m.(*client.Client)
+	// a renamed framework is replaced, its apps are checked before the current framework is deleted
+	if d.Id() != "" && d.HasChange("name") {
+		policy, _ := d.GetChange("deletion_policy")
+		if err := c.CheckFrameworkDeletion(ctx, d.Id(), policy.(string)); err != nil {
+			return err
+		}
+	}
+
+	// the namespace of a framework is changed by replacing the framework,
+	// the replacement is planned even if the new namespace can't be validated yet
+	if d.Id() != "" && d.HasChange("namespace") {
+		if err := d.ForceNew("namespace"); err != nil {
+			return err
+		}
+	}
+
+	if !d.NewValueKnown("app_quota_limit") {
+		return nil
+	}
+	// a framework without app_quota_limit runs unlimited apps
+	quota, quotaSet := d.GetOkExists("app_quota_limit")
+	if unlimited, ok := d.GetOkExists("unlimited_apps"); ok && d.NewValueKnown("unlimited_apps") && !unlimited.(bool) && !quotaSet {
+		return errors.New("app_quota_limit is required when unlimited_apps is false")
+	}
+	if d.Id() != "" && d.HasChange("app_quota_limit") {
+		if err := d.SetNewComputed("apps_remaining"); err != nil {
+			return err
+		}
+	}
+
+	if d.Id() != "" && !d.HasChange("namespace") && !d.HasChange("app_quota_limit") {
+		return nil
+	}
+	if !d.NewValueKnown("name") || !d.NewValueKnown("namespace") {
+		return nil
+	}
+
+	var framework client.Framework
+	helper.TerraformToStruct(map[string]interface{}{
+		"name":      d.Get("name"),
+		"namespace": d.Get("namespace"),
+	}, &framework)
+	if quotaSet {
+		appQuotaLimit := int64(quota.(int))
+		framework.AppQuotaLimit = &appQuotaLimit
 	}
+
+	// a namespace change is refused for a framework with apps
+	return c.ValidateFramework(ctx, &framework, d.Id())
+}
+
+// suppressDefaultNamespace suppresses a diff between the default namespace of a framework and a namespace which is not set.
+func suppressDefaultNamespace(k, old, new string, d *schema.ResourceData) bool {
+	return new == "" && old == "ketch-"+d.Get("name").(string)
 }
 
 func extractFramework(d *schema.ResourceData) *client.Framework {
 	raw := d.Get("")
 	var framework client.Framework
 	helper.TerraformToStruct(raw, &framework)
+	// zero is a valid quota, so the quota is extracted only when it's set
+	if quota, ok := d.GetOkExists("app_quota_limit"); ok {
+		appQuotaLimit := int64(quota.(int))
+		framework.AppQuotaLimit = &appQuotaLimit
+	}
 	return &framework
 }
 
@@ -89,6 +228,23 @@ func resourceFrameworkCreate(ctx context.Context, d *schema.ResourceData, m inte
 
 	d.SetId(framework.Name)
 
+	// an adopted namespace existed before the framework, it is known only when the framework is created
+	err = d.Set("namespace_adopted", framework.NamespaceAdopted)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = c.ApplyFrameworkNamespace(ctx, framework, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("wait_for_ready").(bool) {
+		err = c.WaitForFramework(ctx, framework.Name, d.Timeout(schema.TimeoutCreate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	resourceFrameworkRead(ctx, d, m)
 
 	return diags
@@ -114,7 +270,21 @@ func resourceFrameworkRead(ctx context.Context, d *schema.ResourceData, m interf
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	err = d.Set("app_quota_limit", framework.AppQuotaLimit)
+	// labels and annotations added by ketch controller and kubernetes are not managed
+	err = d.Set("namespace_labels", managedValues(framework.NamespaceLabels))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("namespace_annotations", managedValues(framework.NamespaceAnnotations))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// an unlimited quota is not set
+	if framework.AppQuotaLimit != nil {
+		err = d.Set("app_quota_limit", *framework.AppQuotaLimit)
+	} else {
+		err = d.Set("app_quota_limit", nil)
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -122,6 +292,30 @@ func resourceFrameworkRead(ctx context.Context, d *schema.ResourceData, m interf
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	err = d.Set("phase", framework.Phase)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("message", framework.Message)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("namespace_uid", framework.NamespaceUID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("apps", framework.Apps)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("jobs", framework.Jobs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	err = d.Set("apps_remaining", framework.AppsRemaining)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	return diags
 }
@@ -141,6 +335,15 @@ func resourceFrameworkUpdate(ctx context.Context, d *schema.ResourceData, m inte
 		return diag.FromErr(err)
 	}
 
+	if d.HasChange("namespace_labels") || d.HasChange("namespace_annotations") {
+		framework.RemovedNamespaceLabels = removedKeys(d, "namespace_labels")
+		framework.RemovedNamespaceAnnotations = removedKeys(d, "namespace_annotations")
+		err = c.ApplyFrameworkNamespace(ctx, framework, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	return resourceFrameworkRead(ctx, d, m)
 }
 
@@ -150,7 +353,7 @@ func resourceFrameworkDelete(ctx context.Context, d *schema.ResourceData, m inte
 
 	name := d.Id()
 	c := m.(*client.Client)
-	err := c.DeleteFramework(ctx, name)
+	err := c.DeleteFrameworkWithPolicy(ctx, name, d.Get("deletion_policy").(string), d.Get("namespace_adopted").(bool), d.Timeout(schema.TimeoutDelete))
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -1,9 +1,11 @@
 package ketch
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/require"
 
 	"github.com/brunoa19/ketch-terraform-provider/client"
@@ -14,6 +16,9 @@ func TestExtractFramework(t *testing.T) {
 		"name":            "testfw",
 		"namespace":       "testns",
 		"app_quota_limit": 2,
+		"namespace_labels": map[string]interface{}{
+			"istio-injection": "enabled",
+		},
 		"ingress_controller": []interface{}{
 			map[string]interface{}{
 				"class_name":       "traefik",
@@ -22,18 +27,95 @@ func TestExtractFramework(t *testing.T) {
 				"cluster_issuer":   "test_issuer",
 			}},
 	})
+	appQuotaLimit := int64(2)
 	expected := &client.Framework{
 		Name:          "testfw",
 		Namespace:     "testns",
-		AppQuotaLimit: 2,
+		AppQuotaLimit: &appQuotaLimit,
 		IngressController: &client.IngressControllerSpec{
 			ClassName:       "traefik",
 			ServiceEndpoint: "10.10.10.10",
 			IngressType:     "traefik",
 			ClusterIssuer:   "test_issuer",
 		},
+		NamespaceLabels:      map[string]string{"istio-injection": "enabled"},
+		NamespaceAnnotations: map[string]string{},
 	}
 
 	framework := extractFramework(d)
 	require.Equal(t, expected, framework)
 }
+
+func TestExtractFrameworkAppQuota(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceFramework().Schema, map[string]interface{}{
+		"name":            "testfw",
+		"app_quota_limit": 0,
+	})
+	framework := extractFramework(d)
+	require.NotNil(t, framework.AppQuotaLimit)
+	require.Equal(t, int64(0), *framework.AppQuotaLimit)
+
+	d = schema.TestResourceDataRaw(t, resourceFramework().Schema, map[string]interface{}{
+		"name": "testfw",
+	})
+	framework = extractFramework(d)
+	require.Nil(t, framework.AppQuotaLimit)
+}
+
+// unknownValue is a value of an attribute known only after apply, it's defined by the SDK in an internal package.
+const unknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+func TestFrameworkNamespaceChangeDiff(t *testing.T) {
+	current := schema.TestResourceDataRaw(t, resourceFramework().Schema, map[string]interface{}{
+		"name":      "testfw",
+		"namespace": "testns",
+	})
+	current.SetId("testfw")
+
+	// the quota is unknown until another resource is created, the framework is replaced anyway
+	resource := &schema.Resource{Schema: resourceFramework().Schema, CustomizeDiff: resourceFrameworkCustomizeDiff}
+	diff, err := resource.Diff(context.Background(), current.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":            "testfw",
+		"namespace":       "newns",
+		"app_quota_limit": unknownValue,
+	}), (*client.Client)(nil))
+	require.NoError(t, err)
+	require.True(t, diff.RequiresNew())
+}
+
+func TestFrameworkUnlimitedAppsDiff(t *testing.T) {
+	current := schema.TestResourceDataRaw(t, resourceFramework().Schema, map[string]interface{}{
+		"name": "testfw",
+	})
+	current.SetId("testfw")
+	resource := &schema.Resource{Schema: resourceFramework().Schema, CustomizeDiff: resourceFrameworkCustomizeDiff}
+
+	// limited apps require a quota
+	_, err := resource.Diff(context.Background(), current.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":           "testfw",
+		"unlimited_apps": false,
+	}), (*client.Client)(nil))
+	require.EqualError(t, err, "app_quota_limit is required when unlimited_apps is false")
+
+	diff, err := resource.Diff(context.Background(), current.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":           "testfw",
+		"unlimited_apps": true,
+	}), (*client.Client)(nil))
+	require.NoError(t, err)
+	require.Equal(t, "true", diff.Attributes["unlimited_apps"].New)
+
+	// a framework without a quota runs unlimited apps
+	_, err = resource.Diff(context.Background(), current.State(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name": "testfw",
+	}), (*client.Client)(nil))
+	require.NoError(t, err)
+}
+
+func TestSuppressDefaultNamespace(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceFramework().Schema, map[string]interface{}{
+		"name": "testfw",
+	})
+	require.True(t, suppressDefaultNamespace("namespace", "ketch-testfw", "", d))
+	require.False(t, suppressDefaultNamespace("namespace", "ketch-testfw", "testns", d))
+	require.False(t, suppressDefaultNamespace("namespace", "testns", "", d))
+}
@@ -101,9 +101,24 @@ func resourceJob() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceJobCustomizeDiff,
 	}
 }
 
+func resourceJobCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && !d.HasChange("name") {
+		return nil
+	}
+	if !d.NewValueKnown("name") {
+		return nil
+	}
+
+	// a renamed job replaces the current one, so the current job doesn't conflict with the new name
+	job := &client.Job{Name: d.Get("name").(string)}
+	c := m.(*client.Client)
+	return c.ValidateJob(ctx, job, d.Id())
+}
+
 func extractJob(d *schema.ResourceData) *client.Job {
 	raw := d.Get("")
 	var job client.Job